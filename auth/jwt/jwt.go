@@ -0,0 +1,108 @@
+// Package jwt implements the small subset of RFC 7519 the API needs:
+// HS256-signed access tokens with a fixed set of claims.
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// header - vienintelis palaikomas algoritmas yra HS256
+var header = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Claims - access token'o turinys. SessionID susieja token'ą su api_session
+// eilute, kad jį būtų galima atšaukti anksčiau nei baigiasi galiojimas.
+type Claims struct {
+	Issuer        string `json:"iss,omitempty"`
+	Subject       string `json:"sub"`
+	SessionID     string `json:"sid"`
+	Email         string `json:"email,omitempty"`
+	Role          string `json:"role,omitempty"`
+	EmailVerified bool   `json:"email_verified"`
+	IssuedAt      int64  `json:"iat"`
+	ExpiresAt     int64  `json:"exp"`
+}
+
+type Issuer struct {
+	key    []byte
+	issuer string
+	ttl    time.Duration
+}
+
+func NewIssuer(secret, issuer string, ttl time.Duration) *Issuer {
+	return &Issuer{key: []byte(secret), issuer: issuer, ttl: ttl}
+}
+
+// TTL grąžina access token'ų galiojimo trukmę
+func (i *Issuer) TTL() time.Duration {
+	return i.ttl
+}
+
+// Sign fills in iss, iat and exp and returns the signed token
+func (i *Issuer) Sign(claims Claims) (string, error) {
+	now := time.Now()
+	claims.Issuer = i.issuer
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(i.ttl).Unix()
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	data := header + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return data + "." + i.mac(data), nil
+}
+
+// Parse verifies the signature, issuer and expiry and returns the claims
+func (i *Issuer) Parse(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	// Header turi sutapti tiksliai - taip atmetami "alg":"none" ir kiti algoritmai
+	if parts[0] != header {
+		return nil, ErrInvalidToken
+	}
+
+	data := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(i.mac(data))) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if claims.Issuer != i.issuer || claims.Subject == "" || claims.SessionID == "" {
+		return nil, ErrInvalidToken
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+
+	return &claims, nil
+}
+
+func (i *Issuer) mac(data string) string {
+	h := hmac.New(sha256.New, i.key)
+	h.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,78 @@
+package oauth
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"battleNet/config"
+	"battleNet/models"
+)
+
+type githubProvider struct {
+	baseProvider
+}
+
+// NewGitHub creates the GitHub provider. GitHub is plain OAuth 2.0, so the
+// identity comes from the REST API instead of an id_token.
+func NewGitHub(cfg config.OAuthProviderConfig) Provider {
+	return &githubProvider{baseProvider{
+		name:        "github",
+		displayName: "GitHub",
+		scopes:      []string{"read:user", "user:email"},
+		cfg:         cfg,
+	}}
+}
+
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+func (p *githubProvider) FetchIdentity(ctx context.Context, token *models.TokenResponse) (*Identity, error) {
+	var user githubUser
+	if err := getJSON(ctx, p.cfg.UserInfoURL, token.AccessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, errMissingSubject
+	}
+
+	// /user grąžina tik viešą email, todėl patvirtintą pirminį imame iš /user/emails
+	var emails []githubEmail
+	if err := getJSON(ctx, strings.TrimSuffix(p.cfg.UserInfoURL, "/")+"/emails", token.AccessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		ProviderID: strconv.FormatInt(user.ID, 10),
+		AvatarURL:  user.AvatarURL,
+	}
+	for _, e := range emails {
+		if e.Primary {
+			identity.Email = e.Email
+			identity.EmailVerified = e.Verified
+			break
+		}
+	}
+
+	name := strings.TrimSpace(user.Name)
+	if name == "" {
+		name = user.Login
+	}
+	parts := strings.SplitN(name, " ", 2)
+	identity.FirstName = parts[0]
+	if len(parts) > 1 {
+		identity.LastName = parts[1]
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,42 @@
+package oauth
+
+import (
+	"context"
+
+	"battleNet/config"
+	"battleNet/models"
+)
+
+type googleProvider struct {
+	baseProvider
+}
+
+// NewGoogle creates the Google provider. Endpoints come from config so a
+// local mock OIDC server can be used in tests.
+func NewGoogle(cfg config.OAuthProviderConfig) Provider {
+	return &googleProvider{baseProvider{
+		name:        "google",
+		displayName: "Google",
+		scopes:      []string{"openid", "email", "profile"},
+		cfg:         cfg,
+	}}
+}
+
+func (p *googleProvider) FetchIdentity(ctx context.Context, token *models.TokenResponse) (*Identity, error) {
+	var profile models.OAuthResponse
+	if err := getJSON(ctx, p.cfg.UserInfoURL, token.AccessToken, &profile); err != nil {
+		return nil, err
+	}
+	if profile.ID == "" {
+		return nil, errMissingSubject
+	}
+
+	return &Identity{
+		ProviderID:    profile.ID,
+		Email:         profile.Email,
+		EmailVerified: profile.VerifiedEmail,
+		FirstName:     profile.GivenName,
+		LastName:      profile.FamilyName,
+		AvatarURL:     profile.Picture,
+	}, nil
+}
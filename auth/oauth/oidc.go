@@ -0,0 +1,93 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"battleNet/config"
+	"battleNet/models"
+)
+
+type oidcProvider struct {
+	baseProvider
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// NewOIDC creates a generic OpenID Connect provider. Endpoints are read from
+// the issuer's /.well-known/openid-configuration document.
+func NewOIDC(ctx context.Context, cfg config.OIDCProviderConfig) (Provider, error) {
+	issuer := strings.TrimSuffix(cfg.IssuerURL, "/")
+
+	var doc discoveryDocument
+	if err := getJSON(ctx, issuer+"/.well-known/openid-configuration", "", &doc); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.UserInfoEndpoint == "" {
+		return nil, fmt.Errorf("OIDC discovery document for %s is missing endpoints", issuer)
+	}
+
+	endpoints := cfg.OAuthProviderConfig
+	endpoints.AuthURL = doc.AuthorizationEndpoint
+	endpoints.TokenURL = doc.TokenEndpoint
+	endpoints.UserInfoURL = doc.UserInfoEndpoint
+
+	return &oidcProvider{baseProvider{
+		name:        cfg.Name,
+		displayName: cfg.DisplayName,
+		scopes:      []string{"openid", "email", "profile"},
+		cfg:         endpoints,
+	}}, nil
+}
+
+// flexBool priima ir true, ir "true" - kai kurie tiekėjai email_verified grąžina kaip string
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch t := v.(type) {
+	case bool:
+		*b = flexBool(t)
+	case string:
+		*b = flexBool(strings.EqualFold(t, "true"))
+	}
+	return nil
+}
+
+type oidcUserInfo struct {
+	Subject       string   `json:"sub"`
+	Email         string   `json:"email"`
+	EmailVerified flexBool `json:"email_verified"`
+	GivenName     string   `json:"given_name"`
+	FamilyName    string   `json:"family_name"`
+	Picture       string   `json:"picture"`
+}
+
+func (p *oidcProvider) FetchIdentity(ctx context.Context, token *models.TokenResponse) (*Identity, error) {
+	var info oidcUserInfo
+	if err := getJSON(ctx, p.cfg.UserInfoURL, token.AccessToken, &info); err != nil {
+		return nil, err
+	}
+	if info.Subject == "" {
+		return nil, errMissingSubject
+	}
+
+	return &Identity{
+		ProviderID:    info.Subject,
+		Email:         info.Email,
+		EmailVerified: bool(info.EmailVerified),
+		FirstName:     info.GivenName,
+		LastName:      info.FamilyName,
+		AvatarURL:     info.Picture,
+	}, nil
+}
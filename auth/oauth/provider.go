@@ -0,0 +1,137 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"battleNet/config"
+	"battleNet/models"
+)
+
+// Identity - vartotojo duomenys, gauti iš OAuth tiekėjo
+type Identity struct {
+	ProviderID string
+	Email      string
+	// EmailVerified - true tik tada, kai tiekėjas pats patvirtino email
+	// (Google verified_email, GitHub /user/emails verified, OIDC email_verified).
+	// Pagal jį tapatybė susiejama su esama paskyra, todėl abejojant - false.
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+	AvatarURL     string
+}
+
+// Provider is an OAuth 2.0 authorization-code provider with PKCE support
+type Provider interface {
+	// Name is the URL-safe key used in routes and stored in oauth.provider
+	Name() string
+	// DisplayName is shown on login and profile pages
+	DisplayName() string
+	AuthCodeURL(state, codeChallenge string) string
+	Exchange(ctx context.Context, code, codeVerifier string) (*models.TokenResponse, error)
+	FetchIdentity(ctx context.Context, token *models.TokenResponse) (*Identity, error)
+}
+
+var httpClient = &http.Client{Timeout: 15 * time.Second}
+
+var errMissingSubject = errors.New("provider returned no account id")
+
+// baseProvider implements the parts of the flow that are the same for every provider
+type baseProvider struct {
+	name        string
+	displayName string
+	scopes      []string
+	cfg         config.OAuthProviderConfig
+}
+
+func (p *baseProvider) Name() string {
+	return p.name
+}
+
+func (p *baseProvider) DisplayName() string {
+	return p.displayName
+}
+
+func (p *baseProvider) AuthCodeURL(state, codeChallenge string) string {
+	params := url.Values{}
+	params.Add("client_id", p.cfg.ClientID)
+	params.Add("redirect_uri", p.cfg.RedirectURL)
+	params.Add("response_type", "code")
+	params.Add("scope", strings.Join(p.scopes, " "))
+	params.Add("state", state)
+	params.Add("code_challenge", codeChallenge)
+	params.Add("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(p.cfg.AuthURL, "?") {
+		separator = "&"
+	}
+	return p.cfg.AuthURL + separator + params.Encode()
+}
+
+func (p *baseProvider) Exchange(ctx context.Context, code, codeVerifier string) (*models.TokenResponse, error) {
+	form := url.Values{}
+	form.Add("grant_type", "authorization_code")
+	form.Add("code", code)
+	form.Add("redirect_uri", p.cfg.RedirectURL)
+	form.Add("client_id", p.cfg.ClientID)
+	form.Add("client_secret", p.cfg.ClientSecret)
+	form.Add("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s token endpoint error: %s", p.name, resp.Status)
+	}
+
+	var token models.TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("%s token endpoint returned no access token", p.name)
+	}
+
+	return &token, nil
+}
+
+// getJSON atlieka autorizuotą GET užklausą ir dekoduoja JSON atsakymą
+func getJSON(ctx context.Context, endpoint, accessToken string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", endpoint, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
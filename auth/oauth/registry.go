@@ -0,0 +1,64 @@
+package oauth
+
+import (
+	"context"
+	"log"
+
+	"battleNet/config"
+)
+
+// Registry holds the configured providers in registration order
+type Registry struct {
+	providers map[string]Provider
+	order     []string
+}
+
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string]Provider)}
+}
+
+// Register adds a provider, replacing any provider with the same name
+func (r *Registry) Register(p Provider) {
+	if _, exists := r.providers[p.Name()]; !exists {
+		r.order = append(r.order, p.Name())
+	}
+	r.providers[p.Name()] = p
+}
+
+func (r *Registry) Get(name string) (Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Providers returns all registered providers in registration order
+func (r *Registry) Providers() []Provider {
+	providers := make([]Provider, 0, len(r.order))
+	for _, name := range r.order {
+		providers = append(providers, r.providers[name])
+	}
+	return providers
+}
+
+// NewRegistryFromConfig registers every provider that has a client ID configured
+func NewRegistryFromConfig(ctx context.Context, cfg *config.Config) *Registry {
+	registry := NewRegistry()
+
+	if cfg.GoogleOAuth.ClientID != "" {
+		registry.Register(NewGoogle(cfg.GoogleOAuth))
+	}
+
+	if cfg.GitHubOAuth.ClientID != "" {
+		registry.Register(NewGitHub(cfg.GitHubOAuth))
+	}
+
+	if cfg.OIDC.ClientID != "" && cfg.OIDC.IssuerURL != "" {
+		provider, err := NewOIDC(ctx, cfg.OIDC)
+		if err != nil {
+			log.Printf("⚠️ OIDC provider %s disabled: %v", cfg.OIDC.Name, err)
+		} else {
+			registry.Register(provider)
+		}
+	}
+
+	return registry
+}
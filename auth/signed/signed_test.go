@@ -0,0 +1,61 @@
+package signed
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	s := NewSigner("test-secret")
+	token := s.Sign("verify", "user@example.test", time.Hour)
+
+	payload, err := s.Verify("verify", token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if payload != "user@example.test" {
+		t.Errorf("payload = %q, want %q", payload, "user@example.test")
+	}
+}
+
+func TestVerifyRejectsTamperedTokens(t *testing.T) {
+	s := NewSigner("test-secret")
+	token := s.Sign("verify", "user@example.test", time.Hour)
+	parts := strings.Split(token, ".")
+
+	tests := map[string]string{
+		"other payload": strings.Join([]string{parts[0], "b3RoZXJAZXhhbXBsZS50ZXN0", parts[2], parts[3]}, "."),
+		"later expiry":  strings.Join([]string{parts[0], parts[1], "99999999999", parts[3]}, "."),
+		"bad signature": token[:len(token)-2] + "xx",
+		"other purpose": "reset" + strings.TrimPrefix(token, "verify"),
+		"no purpose":    strings.TrimPrefix(token, "verify."),
+		"empty":         "",
+	}
+	for name, tampered := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.Verify("verify", tampered); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("err = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+
+	// Token'as, pasirašytas kitam tikslui, netinka
+	if _, err := s.Verify("reset", token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify with another purpose: err = %v, want ErrInvalidToken", err)
+	}
+	// Kitu raktu pasirašytas token'as netinka
+	if _, err := NewSigner("other-secret").Verify("verify", token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify with another key: err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestVerifyRejectsExpiredTokens(t *testing.T) {
+	s := NewSigner("test-secret")
+	token := s.Sign("verify", "user@example.test", -time.Second)
+
+	if _, err := s.Verify("verify", token); !errors.Is(err, ErrExpiredToken) {
+		t.Errorf("err = %v, want ErrExpiredToken", err)
+	}
+}
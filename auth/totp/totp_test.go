@@ -0,0 +1,91 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// RFC 6238 Appendix B SHA1 vektoriai. RFC pateikia 8 skaitmenis, mūsų kodai
+// 6 skaitmenų - tai paskutiniai 6 to paties skaičiaus skaitmenys.
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestGenerateRFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	for _, v := range rfc6238Vectors {
+		if got := generate(key, v.unix/period); got != v.code {
+			t.Errorf("generate(T=%d) = %s, want %s", v.unix, got, v.code)
+		}
+	}
+}
+
+func TestValidateRFC6238Vectors(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		step, ok := Validate(rfc6238Secret, v.code, time.Unix(v.unix, 0))
+		if !ok {
+			t.Errorf("Validate(%s, T=%d) rejected a valid code", v.code, v.unix)
+			continue
+		}
+		if want := v.unix / period; step != want {
+			t.Errorf("Validate(%s, T=%d) step = %d, want %d", v.code, v.unix, step, want)
+		}
+	}
+}
+
+// Priimamas vienas žingsnis į abi puses, bet ne daugiau
+func TestValidateWindowSkew(t *testing.T) {
+	const issued = 1111111109 // 081804, žingsnis 37037036
+	code := "081804"
+	step := int64(issued / period)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		ok   bool
+	}{
+		{"same step", time.Unix(issued, 0), true},
+		{"one step later", time.Unix((step+1)*period, 0), true},
+		{"one step earlier", time.Unix((step-1)*period, 0), true},
+		{"two steps later", time.Unix((step+2)*period, 0), false},
+		{"two steps earlier", time.Unix((step-2)*period, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Validate(rfc6238Secret, code, tt.at)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			// Grąžinamas kodo žingsnis, ne dabartinis - pagal jį ClaimStep atmeta pakartotinį panaudojimą
+			if ok && got != step {
+				t.Errorf("step = %d, want the code's step %d", got, step)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsMalformedInput(t *testing.T) {
+	at := time.Unix(59, 0)
+	if _, ok := Validate(rfc6238Secret, "287 082", at); !ok {
+		t.Error("code with a space should be accepted")
+	}
+	for _, code := range []string{"", "28708", "2870820", "000000"} {
+		if _, ok := Validate(rfc6238Secret, code, at); ok {
+			t.Errorf("Validate(%q) accepted an invalid code", code)
+		}
+	}
+	if _, ok := Validate("not base32!", "287082", at); ok {
+		t.Error("Validate accepted a code for an invalid secret")
+	}
+}
@@ -9,27 +9,31 @@ import (
 	"syscall"
 	"time"
 
+	"battleNet/auth/oauth"
 	"battleNet/config"
 	"battleNet/external/tmdb"
 	"battleNet/internal/handlers"
-	"battleNet/middlewaree"
+	"battleNet/internal/router"
+	"battleNet/mail"
+	"battleNet/permissions"
 	"battleNet/repository"
 
 	"github.com/alexedwards/scs/v2"
-	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
 )
 
 var (
 	sessionManager *scs.SessionManager
 	cfg            *config.Config
 	db             *repository.Database
+	policy         *permissions.Policy
 )
 
 func main() {
 	// Load configuration
 	cfg = config.Load()
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("❌ Invalid configuration: %v", err)
+	}
 
 	// Connect to database
 	var err error
@@ -40,26 +44,50 @@ func main() {
 	defer db.Close()
 
 	// Initialize session manager
-	initSessionManager()
+	sessionStore := repository.NewSessionStore(db.Pool, 5*time.Minute)
+	defer sessionStore.StopCleanup()
+	initSessionManager(sessionStore)
 
 	tmdbClient := tmdb.NewClient(cfg.TMDBAPIKey, cfg.TMDBBaseURL)
+	oauthProviders := oauth.NewRegistryFromConfig(context.Background(), cfg)
+
+	mailer, err := mail.NewFromConfig(cfg.Mail)
+	if err != nil {
+		log.Fatalf("❌ Failed to configure mailer: %v", err)
+	}
 
 	// Create repository instances
-	userRepo := repository.NewUserRepository(db.Pool)
-	movieRepo := repository.NewMovieRepository(db.Pool)
-	reviewRepo := repository.NewReviewRepository(db.Pool)
-	watchlistRepo := repository.NewWatchlistRepository(db.Pool)
+	repos := repository.NewRepositories(db.Pool, time.Minute)
+	defer repos.Close()
+
+	// Rolių ir leidimų hierarchija iš DB
+	policy, err = repository.NewRoleRepository(db.Pool).LoadPolicy(context.Background())
+	if err != nil {
+		log.Fatalf("❌ Failed to load role permissions: %v", err)
+	}
 
 	// Initialize handlers
-	handler := handlers.NewHandler(userRepo, movieRepo, reviewRepo, watchlistRepo, cfg.JWTSecret, sessionManager, tmdbClient)
+	handler := handlers.NewHandler(handlers.Deps{
+		Repos:                 repos,
+		SessionManager:        sessionManager,
+		SessionStore:          sessionStore,
+		TMDB:                  tmdbClient,
+		OAuthProviders:        oauthProviders,
+		Mailer:                mailer,
+		Policy:                policy,
+		JWTSecret:             cfg.JWTSecret,
+		TokenSecret:           cfg.TokenSecret,
+		AppBaseURL:            cfg.AppBaseURL,
+		RequireStaffTwoFactor: cfg.RequireStaffTwoFactor,
+	})
 
 	// Setup router
-	router := setupRouter(handler)
+	mux := router.New(handler, sessionManager, policy, cfg)
 
 	// Start server
 	server := &http.Server{
 		Addr:    ":" + cfg.Port,
-		Handler: router,
+		Handler: mux,
 	}
 
 	go func() {
@@ -90,126 +118,12 @@ func main() {
 	log.Println("✅ Server stopped")
 }
 
-func initSessionManager() {
+func initSessionManager(store scs.Store) {
 	sessionManager = scs.New()
+	sessionManager.Store = store
 	sessionManager.Lifetime = 24 * time.Hour
 	sessionManager.Cookie.Persist = true
 	sessionManager.Cookie.Secure = false
 	sessionManager.Cookie.HttpOnly = true
 	sessionManager.Cookie.SameSite = http.SameSiteLaxMode
 }
-
-func setupRouter(handler *handlers.Handler) *chi.Mux {
-	r := chi.NewRouter()
-
-	// Middleware
-	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
-	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(sessionManager.LoadAndSave)
-
-	// CORS middlewaree
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		AllowCredentials: true,
-		MaxAge:           300,
-	}))
-
-	// Static files
-	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
-
-	// Public routes
-	r.Get("/", handler.HandleHome)
-	r.Get("/login", handler.HandleLoginPage)
-	r.Post("/login", handler.HandleLogin)
-	r.Get("/signup", handler.HandleSignupPage)
-	r.Post("/signup", handler.HandleSignup)
-	r.Get("/search", handler.HandleSearchMovies)
-
-	// Protected routes
-	r.Group(func(r chi.Router) {
-		r.Use(middlewaree.RequireAuth(sessionManager))
-
-		r.Get("/dashboard", handler.HandleDashboard)
-		r.Get("/profile", handler.HandleProfile)
-		r.Get("/logout", handler.HandleLogout)
-		r.Get("/movies", handler.HandleMovies)
-		r.Get("/movies/{id}", handler.HandleMovieDetail)
-		r.Get("/watchlist", handler.HandleWatchlist)
-		r.Post("/watchlist/add", handler.HandleAddToWatchlist)
-		r.Post("/reviews", handler.HandleCreateReview)
-		r.Post("/watchlist/remove", handler.HandleRemoveFromWatchlist)
-		r.Get("/profile/edit", handler.HandleEditProfilePage)
-		r.Post("/profile/edit", handler.HandleUpdateProfile)
-		r.Get("/profile/change-password", handler.HandleChangePasswordPage)
-		r.Post("/profile/change-password", handler.HandleChangePassword)
-
-		// Admin routes
-		r.Group(func(r chi.Router) {
-			r.Use(middlewaree.RequireRole(sessionManager, "admin"))
-
-			r.Get("/admin/movies", handler.HandleAdminMovies)
-			r.Get("/admin/movies/create", handler.HandleCreateMoviePage)
-			r.Post("/admin/movies/create", handler.HandleCreateMovie)
-			r.Get("/admin/movies/edit", handler.HandleEditMoviePage)
-			r.Post("/admin/movies/update", handler.HandleUpdateMovie)
-			r.Post("/admin/movies/delete", handler.HandleDeleteMovie)
-
-			r.Post("/admin/movies/import", handler.HandleImportMovie)
-		})
-
-		r.Group(func(r chi.Router) {
-			r.Use(middlewaree.RequireRole(sessionManager, "moderator"))
-
-			r.Get("/moderator/dashboard", handler.HandleModeratorDashboard)
-			r.Get("/moderator/users", handler.HandleModeratorUsers)
-			r.Post("/moderator/users/update-role", handler.HandleModeratorUpdateRole)
-			r.Post("/moderator/users/deactivate", handler.HandleModeratorDeactivateUser)
-
-			r.Post("/moderator/movies/import", handler.HandleImportMovie)
-		})
-	})
-
-	// API routes (REST API)
-	r.Route("/api/v1", func(r chi.Router) {
-		// Public API endpoints
-		r.Get("/movies", handler.HandleAPIMovies)
-		r.Get("/movies/{id}", handler.HandleAPIMovieDetail)
-		r.Get("/reviews", handler.HandleAPIReviews)
-		r.Get("/tmdb/search", handler.HandleAPISearchMovies)
-
-		// Protected API endpoints
-		r.Group(func(r chi.Router) {
-			r.Use(middlewaree.RequireAuthAPI(sessionManager))
-
-			r.Post("/reviews", handler.HandleAPICreateReview)
-			r.Get("/watchlist", handler.HandleAPIWatchlist)
-			r.Post("/watchlist", handler.HandleAPIAddToWatchlist)
-			r.Delete("/watchlist/{movieId}", handler.HandleAPIRemoveFromWatchlist)
-		})
-
-		//Moderator API endpoints
-		r.Group(func(r chi.Router) {
-			r.Use(middlewaree.RequireRoleAPI(sessionManager, "moderator"))
-
-			r.Get("/moderator/users", handler.HandleAPIModeratorUsers)
-			r.Put("/moderator/users/{id}/role", handler.HandleAPIModeratorUpdateRole)
-			r.Delete("/moderator/users/{id}", handler.HandleAPIModeratorDeactivateUser)
-		})
-
-		// Admin API endpoints
-		r.Group(func(r chi.Router) {
-			r.Use(middlewaree.RequireAuthAPI(sessionManager))
-			r.Use(middlewaree.RequireRoleAPI(sessionManager, "admin"))
-
-			r.Post("/movies", handler.HandleAPICreateMovie)
-			r.Put("/movies/{id}", handler.HandleAPIUpdateMovie)
-			r.Delete("/movies/{id}", handler.HandleAPIDeleteMovie)
-		})
-	})
-
-	return r
-}
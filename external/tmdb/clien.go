@@ -28,20 +28,21 @@ func NewClient(apiKey, baseURL string) *Client {
 
 // TMDB filmo struktūra
 type TMDBMovie struct {
-	ID            int     `json:"id"`
-	Title         string  `json:"title"`
-	OriginalTitle string  `json:"original_title"`
-	Overview      string  `json:"overview"`
-	ReleaseDate   string  `json:"release_date"`
-	PosterPath    string  `json:"poster_path"`
-	BackdropPath  string  `json:"backdrop_path"`
-	VoteAverage   float64 `json:"vote_average"`
-	VoteCount     int     `json:"vote_count"`
-	Popularity    float64 `json:"popularity"`
-	Runtime       int     `json:"runtime"`
-	Status        string  `json:"status"`
-	ImdbID        string  `json:"imdb_id"`
-	Genres        []Genre `json:"genres"`
+	ID            int      `json:"id"`
+	Title         string   `json:"title"`
+	OriginalTitle string   `json:"original_title"`
+	Overview      string   `json:"overview"`
+	ReleaseDate   string   `json:"release_date"`
+	PosterPath    string   `json:"poster_path"`
+	BackdropPath  string   `json:"backdrop_path"`
+	VoteAverage   float64  `json:"vote_average"`
+	VoteCount     int      `json:"vote_count"`
+	Popularity    float64  `json:"popularity"`
+	Runtime       int      `json:"runtime"`
+	Status        string   `json:"status"`
+	ImdbID        string   `json:"imdb_id"`
+	Genres        []Genre  `json:"genres"`
+	Credits       *Credits `json:"credits,omitempty"` // tik GetMovieDetails (append_to_response=credits)
 }
 
 type Genre struct {
@@ -119,6 +120,39 @@ func (c *Client) GetMovieDetails(ctx context.Context, tmdbID int) (*TMDBMovie, e
 	return &movie, nil
 }
 
+// Gauti asmens (aktoriaus, režisieriaus) informaciją pagal TMDB ID
+func (c *Client) GetPerson(ctx context.Context, tmdbID int) (*TMDBPerson, error) {
+	endpoint := fmt.Sprintf("%s/person/%d", c.baseURL, tmdbID)
+
+	params := url.Values{}
+	params.Add("api_key", c.apiKey)
+	params.Add("language", "en-US")
+
+	url := fmt.Sprintf("%s?%s", endpoint, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("TMDB API error: %s", resp.Status)
+	}
+
+	var person TMDBPerson
+	if err := json.NewDecoder(resp.Body).Decode(&person); err != nil {
+		return nil, err
+	}
+
+	return &person, nil
+}
+
 // Populiariausi filmai
 func (c *Client) GetPopularMovies(ctx context.Context, page int) (*SearchResponse, error) {
 	endpoint := fmt.Sprintf("%s/movie/popular", c.baseURL)
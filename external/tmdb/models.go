@@ -11,11 +11,26 @@ type CastMember struct {
 	Name        string `json:"name"`
 	Character   string `json:"character"`
 	ProfilePath string `json:"profile_path"`
+	Order       int    `json:"order"` // billing order, 0 - pagrindinis vaidmuo
 }
 
 type CrewMember struct {
-	ID         int    `json:"id"`
-	Name       string `json:"name"`
-	Job        string `json:"job"`
-	Department string `json:"department"`
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Job         string `json:"job"`
+	Department  string `json:"department"`
+	ProfilePath string `json:"profile_path"`
+}
+
+// TMDBPerson - /person/{id} atsakymas. Datos YYYY-MM-DD arba null.
+type TMDBPerson struct {
+	ID                 int    `json:"id"`
+	Name               string `json:"name"`
+	Biography          string `json:"biography"`
+	Birthday           string `json:"birthday"`
+	Deathday           string `json:"deathday"`
+	PlaceOfBirth       string `json:"place_of_birth"`
+	ProfilePath        string `json:"profile_path"`
+	KnownForDepartment string `json:"known_for_department"`
+	ImdbID             string `json:"imdb_id"`
 }
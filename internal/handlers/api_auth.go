@@ -0,0 +1,276 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"battleNet/auth/jwt"
+	"battleNet/internal/response"
+	"battleNet/middlewaree"
+	"battleNet/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+	jwtIssuer       = "battlenet"
+)
+
+// tokenResponse - /api/v1/auth/token ir /api/v1/auth/refresh atsakymas
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// HandleAPIToken - išduoda access ir refresh token'us pagal email ir slaptažodį.
+// Vartotojams su 2FA reikia ir "otp" (TOTP arba atkūrimo kodo).
+func (h *Handler) HandleAPIToken(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		OTP      string `json:"otp"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.Error(w, r, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request body")
+		return
+	}
+
+	if remaining := h.loginLockedFor(r.Context(), request.Email, clientIP(r)); remaining > 0 {
+		h.recordLoginAttempt(r.Context(), r, request.Email, nil, false, "locked")
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(remaining.Seconds()))))
+		response.Error(w, r, http.StatusTooManyRequests, response.CodeTooManyRequests, "Too many failed login attempts")
+		return
+	}
+
+	user, suspended, err := h.loadLoginUser(r.Context(), request.Email)
+	if err != nil {
+		log.Printf("API token request failed for email %s: %v", request.Email, err)
+		h.recordLoginFailure(r.Context(), r, request.Email, nil, "unknown_email")
+		response.Error(w, r, http.StatusUnauthorized, response.CodeInvalidCredentials, "Invalid email or password")
+		return
+	}
+
+	if suspended != nil {
+		if suspended.User.PasswordHash == "" || bcrypt.CompareHashAndPassword([]byte(suspended.User.PasswordHash), []byte(request.Password)) != nil {
+			log.Printf("Invalid API token password for user %s", request.Email)
+			h.recordLoginFailure(r.Context(), r, request.Email, &suspended.User.UserID, "invalid_password")
+			response.Error(w, r, http.StatusUnauthorized, response.CodeInvalidCredentials, "Invalid email or password")
+			return
+		}
+		h.recordLoginAttempt(r.Context(), r, request.Email, &suspended.User.UserID, false, "suspended")
+		response.Write(w, r, &response.Problem{
+			Status: http.StatusForbidden,
+			Code:   response.CodeAccountSuspended,
+			Detail: suspensionMessage(suspended.Suspension),
+			Extensions: map[string]any{
+				"reason":     suspended.Suspension.Reason,
+				"expires_at": suspended.Suspension.ExpiresAt,
+			},
+		})
+		return
+	}
+
+	// OAuth vartotojai be slaptažodžio token'o šiuo būdu gauti negali
+	if user.PasswordHash == "" || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(request.Password)) != nil {
+		log.Printf("Invalid API token password for user %s", request.Email)
+		h.recordLoginFailure(r.Context(), r, request.Email, &user.UserID, "invalid_password")
+		response.Error(w, r, http.StatusUnauthorized, response.CodeInvalidCredentials, "Invalid email or password")
+		return
+	}
+
+	twoFactor, err := h.twoFactorRepo.IsEnabled(r.Context(), user.UserID)
+	if err != nil {
+		log.Printf("Failed to check 2FA for user %s: %v", user.UserID, err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Internal server error")
+		return
+	}
+	if twoFactor {
+		if request.OTP == "" {
+			response.Error(w, r, http.StatusUnauthorized, response.CodeOTPRequired, "Two-factor code required")
+			return
+		}
+		valid, err := h.verifySecondFactor(r.Context(), user.UserID, request.OTP)
+		if err != nil {
+			log.Printf("Failed to verify 2FA code for user %s: %v", user.UserID, err)
+			response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Internal server error")
+			return
+		}
+		if !valid {
+			log.Printf("Invalid API token 2FA code for user %s", request.Email)
+			h.recordLoginFailure(r.Context(), r, request.Email, &user.UserID, "invalid_2fa")
+			response.Error(w, r, http.StatusUnauthorized, response.CodeInvalidOTP, "Invalid two-factor code")
+			return
+		}
+	}
+
+	refreshToken, err := randomToken(32)
+	if err != nil {
+		log.Printf("Failed to generate refresh token: %v", err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Internal server error")
+		return
+	}
+
+	sessionID, err := h.apiSessionRepo.CreateSession(r.Context(), user.UserID, hashToken(refreshToken), time.Now().Add(refreshTokenTTL), twoFactor, r.UserAgent(), clientIP(r))
+	if err != nil {
+		log.Printf("Failed to create API session for user %s: %v", user.UserID, err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Internal server error")
+		return
+	}
+
+	h.recordLoginSuccess(r.Context(), r, user)
+	log.Printf("API token issued for user %s (session %s)", user.Email, sessionID)
+	h.writeTokenResponse(w, r, user, sessionID, refreshToken)
+}
+
+// HandleAPIRefreshToken - pakeičia refresh token'ą nauja token'ų pora
+func (h *Handler) HandleAPIRefreshToken(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.RefreshToken == "" {
+		response.Error(w, r, http.StatusBadRequest, response.CodeBadRequest, "refresh_token is required")
+		return
+	}
+
+	newRefreshToken, err := randomToken(32)
+	if err != nil {
+		log.Printf("Failed to generate refresh token: %v", err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Internal server error")
+		return
+	}
+
+	sessionID, _, err := h.apiSessionRepo.RotateRefreshToken(r.Context(), hashToken(request.RefreshToken), hashToken(newRefreshToken), time.Now().Add(refreshTokenTTL))
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.Error(w, r, http.StatusUnauthorized, response.CodeInvalidToken, "Invalid or expired refresh token")
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to rotate refresh token: %v", err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Internal server error")
+		return
+	}
+
+	// Vartotojas galėjo būti deaktyvuotas nuo paskutinio atnaujinimo
+	user, _, err := h.apiSessionRepo.GetSessionUser(r.Context(), sessionID)
+	if err != nil {
+		response.Error(w, r, http.StatusUnauthorized, response.CodeInvalidToken, "Invalid or expired refresh token")
+		return
+	}
+
+	h.writeTokenResponse(w, r, user, sessionID, newRefreshToken)
+}
+
+// HandleAPIRevokeToken - atšaukia API sesiją pagal refresh token'ą arba
+// Bearer access token'ą. Kaip RFC 7009, nežinomas token'as nėra klaida.
+func (h *Handler) HandleAPIRevokeToken(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			response.Error(w, r, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request body")
+			return
+		}
+	}
+
+	var err error
+	switch bearer, hasBearer := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); {
+	case request.RefreshToken != "":
+		err = h.apiSessionRepo.RevokeByRefreshToken(r.Context(), hashToken(request.RefreshToken))
+	case hasBearer:
+		claims, parseErr := h.jwtIssuer.Parse(strings.TrimSpace(bearer))
+		if parseErr != nil {
+			response.Error(w, r, http.StatusUnauthorized, response.CodeInvalidToken, "Invalid access token")
+			return
+		}
+		sessionID, parseErr := uuid.Parse(claims.SessionID)
+		if parseErr != nil {
+			response.Error(w, r, http.StatusUnauthorized, response.CodeInvalidToken, "Invalid access token")
+			return
+		}
+		err = h.apiSessionRepo.RevokeSession(r.Context(), sessionID)
+	default:
+		response.Error(w, r, http.StatusBadRequest, response.CodeBadRequest, "refresh_token or Bearer token is required")
+		return
+	}
+
+	if err != nil {
+		log.Printf("Failed to revoke API session: %v", err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Internal server error")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{"status": "success", "message": "Token revoked"})
+}
+
+// AuthenticateBearer implements middlewaree.BearerAuthenticator. Personal
+// access tokens are recognised by their prefix; anything else must be a JWT.
+// Besides the signature it checks that the API session is still active, so
+// revoked sessions and deactivated users are rejected before the token expires.
+func (h *Handler) AuthenticateBearer(ctx context.Context, token string) (*middlewaree.Principal, error) {
+	if strings.HasPrefix(token, personalAccessTokenPrefix) {
+		return h.authenticatePersonalAccessToken(ctx, token)
+	}
+
+	claims, err := h.jwtIssuer.Parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return nil, jwt.ErrInvalidToken
+	}
+
+	user, twoFactor, err := h.apiSessionRepo.GetSessionUser(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if user.UserID.String() != claims.Subject {
+		return nil, jwt.ErrInvalidToken
+	}
+
+	return &middlewaree.Principal{
+		UserID:        user.UserID.String(),
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
+		TwoFactor:     twoFactor,
+	}, nil
+}
+
+func (h *Handler) writeTokenResponse(w http.ResponseWriter, r *http.Request, user *models.User, sessionID uuid.UUID, refreshToken string) {
+	accessToken, err := h.jwtIssuer.Sign(jwt.Claims{
+		Subject:       user.UserID.String(),
+		SessionID:     sessionID.String(),
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
+	})
+	if err != nil {
+		log.Printf("Failed to sign access token: %v", err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Internal server error")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, tokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(h.jwtIssuer.TTL().Seconds()),
+		RefreshToken: refreshToken,
+	})
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+
+	"battleNet/internal/response"
+	"battleNet/middlewaree"
+	"battleNet/models"
+	"battleNet/templates"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+)
+
+// Audito įvykių tipai - sutampa su leidimais, kurių reikia veiksmui
+const (
+	auditMovieCreate    = "movie.create"
+	auditMovieUpdate    = "movie.update"
+	auditMovieDelete    = "movie.delete"
+	auditMovieImport    = "movie.import"
+	auditUserRoleUpdate = "user.role.update"
+	auditUserDeactivate = "user.deactivate"
+	auditUserReactivate = "user.reactivate"
+	auditUserUnlock     = "user.unlock"
+)
+
+const (
+	auditPageSize    = 50
+	auditMaxPageSize = 200
+)
+
+// auditChange - vieno lauko reikšmė prieš ir po veiksmo
+type auditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// recordAudit įrašo privilegijuotą veiksmą. before/after - objekto būsena
+// prieš ir po (nil kuriant arba trinant); saugomi tik pasikeitę laukai.
+// Klaida tik logujama - veiksmas jau atliktas.
+func (h *Handler) recordAudit(r *http.Request, action, targetType, targetID string, before, after any) {
+	event := &models.AuditEvent{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		IPAddress:  clientIP(r),
+		RequestID:  middleware.GetReqID(r.Context()),
+	}
+
+	if actorID, err := uuid.Parse(h.contextString(r, middlewaree.UserIDKey, "userID")); err == nil {
+		event.ActorID = &actorID
+	}
+	event.ActorEmail = h.contextString(r, middlewaree.EmailKey, "email")
+
+	changes, err := json.Marshal(auditChanges(before, after))
+	if err != nil {
+		log.Printf("Failed to encode audit changes for %s %s: %v", action, targetID, err)
+	} else {
+		event.Changes = changes
+	}
+
+	if err := h.auditRepo.RecordEvent(r.Context(), event); err != nil {
+		log.Printf("Failed to record audit event %s on %s %s: %v", action, targetType, targetID, err)
+	}
+}
+
+// contextString paima reikšmę iš middleware konteksto, o jei jos nėra - iš sesijos
+func (h *Handler) contextString(r *http.Request, key any, sessionKey string) string {
+	if value, ok := r.Context().Value(key).(string); ok && value != "" {
+		return value
+	}
+	return h.sessionManager.GetString(r.Context(), sessionKey)
+}
+
+// auditChanges palygina JSON pavidalo objektus ir grąžina pasikeitusius laukus
+func auditChanges(before, after any) map[string]auditChange {
+	beforeFields := auditFields(before)
+	afterFields := auditFields(after)
+
+	changes := make(map[string]auditChange)
+	for key, value := range beforeFields {
+		if other := afterFields[key]; !reflect.DeepEqual(value, other) {
+			changes[key] = auditChange{Before: value, After: afterFields[key]}
+		}
+	}
+	for key, value := range afterFields {
+		if _, ok := beforeFields[key]; !ok && value != nil {
+			changes[key] = auditChange{After: value}
+		}
+	}
+
+	delete(changes, "updated_at")
+	return changes
+}
+
+func auditFields(v any) map[string]any {
+	fields := map[string]any{}
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return fields
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		// Ne objektas (pvz. string) - saugomas kaip viena reikšmė
+		var value any
+		json.Unmarshal(data, &value)
+		return map[string]any{"value": value}
+	}
+	return fields
+}
+
+// parseAuditFilter nuskaito filtrus iš query: actor, action, from, to (YYYY-MM-DD
+// arba RFC 3339; "to" data įskaitoma visa), limit ir offset
+func parseAuditFilter(query url.Values) (models.AuditFilter, error) {
+	filter := models.AuditFilter{
+		Actor:  query.Get("actor"),
+		Action: query.Get("action"),
+		Limit:  auditPageSize,
+	}
+
+	if from := query.Get("from"); from != "" {
+		t, err := parseAuditTime(from, false)
+		if err != nil {
+			return filter, errors.New("invalid from date")
+		}
+		filter.From = &t
+	}
+	if to := query.Get("to"); to != "" {
+		t, err := parseAuditTime(to, true)
+		if err != nil {
+			return filter, errors.New("invalid to date")
+		}
+		filter.To = &t
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return filter, errors.New("invalid limit")
+		}
+		filter.Limit = min(n, auditMaxPageSize)
+	}
+	if offset := query.Get("offset"); offset != "" {
+		n, err := strconv.Atoi(offset)
+		if err != nil || n < 0 {
+			return filter, errors.New("invalid offset")
+		}
+		filter.Offset = n
+	}
+
+	return filter, nil
+}
+
+func parseAuditTime(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return t, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// HandleAdminAudit - audito žurnalo puslapis
+func (h *Handler) HandleAdminAudit(w http.ResponseWriter, r *http.Request) {
+	email := h.sessionManager.GetString(r.Context(), "email")
+	role := h.sessionManager.GetString(r.Context(), "role")
+
+	query := r.URL.Query()
+	view := templates.AuditLogView{
+		Actor:  query.Get("actor"),
+		Action: query.Get("action"),
+		From:   query.Get("from"),
+		To:     query.Get("to"),
+	}
+
+	filter, err := parseAuditFilter(query)
+	if err != nil {
+		view.Error = "Invalid filter: " + err.Error()
+	} else {
+		view.Events, err = h.auditRepo.GetEvents(r.Context(), filter)
+		if err != nil {
+			log.Printf("Error getting audit events: %v", err)
+			view.Error = "Failed to load audit events"
+		}
+	}
+
+	view.Actions, err = h.auditRepo.GetActions(r.Context())
+	if err != nil {
+		log.Printf("Error getting audit actions: %v", err)
+	}
+
+	// Puslapiavimas per offset
+	if filter.Offset > 0 {
+		prev := cloneQuery(query)
+		prev.Set("offset", strconv.Itoa(max(filter.Offset-filter.Limit, 0)))
+		view.PrevURL = "/admin/audit?" + prev.Encode()
+	}
+	if len(view.Events) == filter.Limit {
+		next := cloneQuery(query)
+		next.Set("offset", strconv.Itoa(filter.Offset+filter.Limit))
+		view.NextURL = "/admin/audit?" + next.Encode()
+	}
+
+	component := templates.AdminAuditPage(email, role, view)
+	component.Render(r.Context(), w)
+}
+
+func cloneQuery(query url.Values) url.Values {
+	clone := make(url.Values, len(query))
+	for key, values := range query {
+		clone[key] = append([]string(nil), values...)
+	}
+	return clone
+}
+
+// HandleAPIAdminAudit - GET /api/v1/admin/audit
+func (h *Handler) HandleAPIAdminAudit(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, response.CodeBadRequest, err.Error())
+		return
+	}
+
+	events, err := h.auditRepo.GetEvents(r.Context(), filter)
+	if err != nil {
+		log.Printf("Error getting audit events: %v", err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to load audit events")
+		return
+	}
+	if events == nil {
+		events = []models.AuditEvent{}
+	}
+
+	response.JSON(w, http.StatusOK, map[string]any{
+		"events": events,
+		"limit":  filter.Limit,
+		"offset": filter.Offset,
+	})
+}
@@ -3,8 +3,12 @@ package handlers
 import (
 	"battleNet/models"
 	"log"
+	"net"
 	"net/http"
+	"strings"
 
+	"battleNet/internal/validation"
+	"battleNet/middlewaree"
 	"battleNet/templates"
 
 	"golang.org/x/crypto/bcrypt"
@@ -12,7 +16,7 @@ import (
 
 // HandleLoginPage displays login page
 func (h *Handler) HandleLoginPage(w http.ResponseWriter, r *http.Request) {
-	component := templates.LoginPage()
+	component := templates.LoginPage(h.oauthProviderList())
 	component.Render(r.Context(), w)
 }
 
@@ -26,38 +30,45 @@ func (h *Handler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	email := r.FormValue("email")
 	password := r.FormValue("password")
 
+	// Brute-force apsauga: užblokuota paskyra ar IP slaptažodžio net netikrina
+	if remaining := h.loginLockedFor(r.Context(), email, clientIP(r)); remaining > 0 {
+		h.recordLoginAttempt(r.Context(), r, email, nil, false, "locked")
+		h.renderLogin(w, r, lockoutMessage(remaining))
+		return
+	}
+
 	// Get user from database
-	user, err := h.userRepo.GetUserByEmail(r.Context(), email)
+	user, suspended, err := h.loadLoginUser(r.Context(), email)
 	if err != nil {
 		log.Printf("Login failed for email %s: %v", email, err)
-		component := templates.LoginPageWithError("Invalid email or password")
-		component.Render(r.Context(), w)
+		h.recordLoginFailure(r.Context(), r, email, nil, "unknown_email")
+		h.renderLogin(w, r, "Invalid email or password")
+		return
+	}
+
+	// Suspenduotam vartotojui priežastis rodoma tik įvedus teisingą slaptažodį
+	if suspended != nil {
+		if bcrypt.CompareHashAndPassword([]byte(suspended.User.PasswordHash), []byte(password)) != nil {
+			log.Printf("Invalid password for user %s", email)
+			h.recordLoginFailure(r.Context(), r, email, &suspended.User.UserID, "invalid_password")
+			h.renderLogin(w, r, "Invalid email or password")
+			return
+		}
+		h.recordLoginAttempt(r.Context(), r, email, &suspended.User.UserID, false, "suspended")
+		h.renderLogin(w, r, suspensionMessage(suspended.Suspension))
 		return
 	}
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
 		log.Printf("Invalid password for user %s", email)
-		component := templates.LoginPageWithError("Invalid email or password")
-		component.Render(r.Context(), w)
+		h.recordLoginFailure(r.Context(), r, email, &user.UserID, "invalid_password")
+		h.renderLogin(w, r, "Invalid email or password")
 		return
 	}
 
-	// Update last login
-	if err := h.userRepo.UpdateLastLogin(r.Context(), user.UserID); err != nil {
-		log.Printf("Failed to update last login for user %s: %v", user.UserID, err)
-	}
-
-	// Set session data
-	h.sessionManager.Put(r.Context(), "userID", user.UserID.String())
-	h.sessionManager.Put(r.Context(), "email", user.Email)
-	h.sessionManager.Put(r.Context(), "role", user.Role)
-	h.sessionManager.Put(r.Context(), "name", user.FirstName+" "+user.LastName)
-	h.sessionManager.Put(r.Context(), "username", user.Username)
-	h.sessionManager.Put(r.Context(), "authenticated", true)
-
-	log.Printf("User logged in: %s (role: %s)", user.Email, user.Role)
-	http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+	// Set session data (or continue with the 2FA step)
+	h.completeLogin(w, r, user, "password")
 }
 
 // HandleSignupPage displays signup page
@@ -74,26 +85,23 @@ func (h *Handler) HandleSignup(w http.ResponseWriter, r *http.Request) {
 	}
 
 	signupReq := models.SignupRequest{
-		Email:           r.FormValue("email"),
+		Email:           strings.TrimSpace(r.FormValue("email")),
 		Password:        r.FormValue("password"),
 		ConfirmPassword: r.FormValue("confirm_password"),
-		FirstName:       r.FormValue("first_name"),
-		LastName:        r.FormValue("last_name"),
-		Username:        r.FormValue("username"),
+		FirstName:       strings.TrimSpace(r.FormValue("first_name")),
+		LastName:        strings.TrimSpace(r.FormValue("last_name")),
+		Username:        strings.TrimSpace(r.FormValue("username")),
 	}
 
-	// Validate passwords match
-	if signupReq.Password != signupReq.ConfirmPassword {
-		component := templates.SignupPageWithError("Passwords do not match")
-		component.Render(r.Context(), w)
+	form, v, err := h.validateSignup(r, &signupReq)
+	if err != nil {
+		log.Printf("Error validating signup: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
-
-	// Check if user already exists
-	_, err := h.userRepo.GetUserByEmail(r.Context(), signupReq.Email)
-	if err == nil {
-		component := templates.SignupPageWithError("Email already registered")
-		component.Render(r.Context(), w)
+	if !v.Valid() {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		templates.SignupFormPage(form, "").Render(r.Context(), w)
 		return
 	}
 
@@ -118,20 +126,101 @@ func (h *Handler) HandleSignup(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.userRepo.CreateUser(r.Context(), user); err != nil {
 		log.Printf("Failed to create user: %v", err)
-		component := templates.SignupPageWithError("Failed to create account")
+		component := templates.SignupFormPage(form, "Failed to create account")
 		component.Render(r.Context(), w)
 		return
 	}
 
+	if err := h.sendVerificationEmail(r.Context(), user); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+	}
+
 	// Auto-login after signup
+	if err := h.startUserSession(r, user); err != nil {
+		log.Printf("Failed to start session for user %s: %v", user.Email, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("New user registered: %s (id: %s)", user.Email, user.UserID)
+	http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+}
+
+// validateSignup tikrina registracijos laukus, įskaitant el. pašto ir
+// vartotojo vardo unikalumą. Grąžinta forma tinka pakartotiniam atvaizdavimui.
+func (h *Handler) validateSignup(r *http.Request, req *models.SignupRequest) (templates.SignupForm, *validation.Validator, error) {
+	v := validation.New()
+
+	v.Required("email", req.Email)
+	v.MaxLength("email", req.Email, 255)
+	v.Email("email", req.Email)
+	v.Required("first_name", req.FirstName)
+	v.MaxLength("first_name", req.FirstName, 100)
+	v.Required("last_name", req.LastName)
+	v.MaxLength("last_name", req.LastName, 100)
+	v.Required("username", req.Username)
+	v.MinLength("username", req.Username, 3)
+	v.MaxLength("username", req.Username, 100)
+	v.Username("username", req.Username)
+	v.Password("password", req.Password)
+	v.Check(req.Password == req.ConfirmPassword, "confirm_password", "does not match the password")
+
+	form := templates.SignupForm{
+		Email:     req.Email,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Username:  req.Username,
+		Errors:    v.Errors,
+	}
+
+	err := v.Unique("email", "is already registered", func() (bool, error) {
+		return h.userRepo.EmailExists(r.Context(), req.Email)
+	})
+	if err != nil {
+		return form, v, err
+	}
+	err = v.Unique("username", "is already taken", func() (bool, error) {
+		return h.userRepo.UsernameExists(r.Context(), req.Username)
+	})
+	return form, v, err
+}
+
+// renderLogin parodo prisijungimo formą su klaidos pranešimu
+func (h *Handler) renderLogin(w http.ResponseWriter, r *http.Request, errorMsg string) {
+	component := templates.LoginPageWithError(errorMsg, h.oauthProviderList())
+	component.Render(r.Context(), w)
+}
+
+// startUserSession renews the session token and stores the logged in user's data
+func (h *Handler) startUserSession(r *http.Request, user *models.User) error {
+	if err := h.sessionManager.RenewToken(r.Context()); err != nil {
+		return err
+	}
+
 	h.sessionManager.Put(r.Context(), "userID", user.UserID.String())
 	h.sessionManager.Put(r.Context(), "email", user.Email)
 	h.sessionManager.Put(r.Context(), "role", user.Role)
 	h.sessionManager.Put(r.Context(), "name", user.FirstName+" "+user.LastName)
+	h.sessionManager.Put(r.Context(), "username", user.Username)
+	h.sessionManager.Put(r.Context(), "email_verified", user.EmailVerified)
+	h.sessionManager.Put(r.Context(), "two_factor", false)
 	h.sessionManager.Put(r.Context(), "authenticated", true)
+	// Naujas CSRF token'as po prisijungimo
+	middlewaree.RenewCSRFToken(r.Context())
 
-	log.Printf("New user registered: %s (id: %s)", user.Email, user.UserID)
-	http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+	// Session store'as šias reikšmes įrašo į user_session stulpelius
+	h.sessionManager.Put(r.Context(), "device_info", r.UserAgent())
+	h.sessionManager.Put(r.Context(), "ip_address", clientIP(r))
+	return nil
+}
+
+// clientIP grąžina kliento IP be porto. RemoteAddr pakeičiamas tik patikimo
+// proxy antraštėmis (middlewaree.RealIP), todėl kliento jo suklastoti negali.
+func clientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
 }
 
 // HandleLogout logs out user
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"battleNet/middlewaree"
+)
+
+// Prisijungimo ribojimo IP raktas neturi priklausyti nuo kliento antraščių
+func TestClientIPIgnoresSpoofedForwardedHeaders(t *testing.T) {
+	var got []string
+	handler := middlewaree.RealIP(nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, clientIP(r))
+	}))
+
+	for _, spoofed := range []string{"198.51.100.1", "198.51.100.2", "192.0.2.55"} {
+		req := httptest.NewRequest(http.MethodPost, "/login", nil)
+		req.RemoteAddr = "203.0.113.7:51000"
+		req.Header.Set("X-Forwarded-For", spoofed)
+		req.Header.Set("X-Real-IP", spoofed)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	for _, ip := range got {
+		if ip != "203.0.113.7" {
+			t.Errorf("clientIP = %q, want the peer address 203.0.113.7", ip)
+		}
+	}
+}
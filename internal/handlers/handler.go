@@ -1,46 +1,104 @@
 package handlers
 
 import (
+	"battleNet/auth/jwt"
+	"battleNet/auth/oauth"
+	"battleNet/auth/signed"
 	"battleNet/external/tmdb"
+	"battleNet/internal/response"
+	"battleNet/internal/validation"
+	"battleNet/mail"
 	"battleNet/models"
+	"battleNet/permissions"
 	"battleNet/repository"
 	"battleNet/templates"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alexedwards/scs/v2"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
 type Handler struct {
-	userRepo       *repository.UserRepository
-	movieRepo      *repository.MovieRepository
-	reviewRepo     *repository.ReviewRepository
-	watchlistRepo  *repository.WatchlistRepository
-	jwtSecret      string
-	sessionManager *scs.SessionManager
-	tmdbClient     *tmdb.Client
+	userRepo          *repository.UserRepository
+	movieRepo         *repository.MovieRepository
+	reviewRepo        *repository.ReviewRepository
+	watchlistRepo     *repository.WatchlistRepository
+	oauthRepo         *repository.OAuthRepository
+	passwordResetRepo *repository.PasswordResetRepository
+	apiSessionRepo    *repository.APISessionRepository
+	patRepo           *repository.PersonalAccessTokenRepository
+	twoFactorRepo     *repository.TwoFactorRepository
+	loginAttemptRepo  *repository.LoginAttemptRepository
+	auditRepo         *repository.AuditRepository
+	suspensionRepo    *repository.SuspensionRepository
+	personRepo        *repository.PersonRepository
+	sessionManager    *scs.SessionManager
+	sessionStore      *repository.SessionStore
+	tmdbClient        *tmdb.Client
+	oauthProviders    *oauth.Registry
+	mailer            mail.Mailer
+	policy            *permissions.Policy
+	signer            *signed.Signer
+	jwtIssuer         *jwt.Issuer
+	appBaseURL        string
+	// requireStaffTwoFactor - admin ir moderator rolėms 2FA privalomas
+	requireStaffTwoFactor bool
 }
 
-func NewHandler(
-	userRepo *repository.UserRepository,
-	movieRepo *repository.MovieRepository,
-	reviewRepo *repository.ReviewRepository,
-	watchlistRepo *repository.WatchlistRepository,
-	jwtSecret string,
-	sessionManager *scs.SessionManager,
-	tmdbClient *tmdb.Client,
-) *Handler {
+// Deps - Handler priklausomybės. Laukai vardiniai, kad vienodo tipo
+// reikšmių nebūtų galima netyčia sukeisti vietomis.
+type Deps struct {
+	Repos          *repository.Repositories
+	SessionManager *scs.SessionManager
+	SessionStore   *repository.SessionStore
+	TMDB           *tmdb.Client
+	OAuthProviders *oauth.Registry
+	Mailer         mail.Mailer
+	Policy         *permissions.Policy
+	// JWTSecret pasirašo API access token'us, TokenSecret - el. laiškų token'us
+	JWTSecret   string
+	TokenSecret string
+	AppBaseURL  string
+	// RequireStaffTwoFactor - admin ir moderator rolėms 2FA privalomas
+	RequireStaffTwoFactor bool
+}
+
+func NewHandler(deps Deps) *Handler {
+	repos := deps.Repos
 	return &Handler{
-		userRepo:       userRepo,
-		movieRepo:      movieRepo,
-		reviewRepo:     reviewRepo,
-		watchlistRepo:  watchlistRepo,
-		jwtSecret:      jwtSecret,
-		sessionManager: sessionManager,
-		tmdbClient:     tmdbClient,
+		userRepo:          repos.Users,
+		movieRepo:         repos.Movies,
+		reviewRepo:        repos.Reviews,
+		watchlistRepo:     repos.Watchlist,
+		oauthRepo:         repos.OAuth,
+		passwordResetRepo: repos.PasswordResets,
+		apiSessionRepo:    repos.APISessions,
+		patRepo:           repos.PersonalAccessTokens,
+		twoFactorRepo:     repos.TwoFactor,
+		loginAttemptRepo:  repos.LoginAttempts,
+		auditRepo:         repos.Audit,
+		suspensionRepo:    repos.Suspensions,
+		personRepo:        repos.People,
+		sessionManager:    deps.SessionManager,
+		sessionStore:      deps.SessionStore,
+		tmdbClient:        deps.TMDB,
+		oauthProviders:    deps.OAuthProviders,
+		mailer:            deps.Mailer,
+		policy:            deps.Policy,
+		signer:            signed.NewSigner(deps.TokenSecret),
+		jwtIssuer:         jwt.NewIssuer(deps.JWTSecret, jwtIssuer, accessTokenTTL),
+		appBaseURL:        deps.AppBaseURL,
+
+		requireStaffTwoFactor: deps.RequireStaffTwoFactor,
 	}
 }
 
@@ -74,97 +132,74 @@ func (h *Handler) HandleCreateMoviePage(w http.ResponseWriter, r *http.Request)
 	role := h.sessionManager.GetString(r.Context(), "role")
 
 	// NAUDOJAME TEMPLATE'Ą iš create_movie.templ
-	component := templates.CreateMoviePage(email, role)
+	form := templates.MovieForm{Status: "Released", GenreOptions: h.genreNames(r.Context())}
+	component := templates.CreateMoviePage(email, role, form)
 	component.Render(r.Context(), w)
 }
 
-// HandleCreateMovie - apdoroja filmo kūrimą
+// HandleCreateMovie - apdoroja filmo kūrimą. Klaidos atveju forma
+// parodoma iš naujo su įvestomis reikšmėmis ir klaidomis prie laukų.
 func (h *Handler) HandleCreateMovie(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
 		http.Error(w, "Bad request", http.StatusBadRequest)
 		return
 	}
 
-	// Gauti duomenis (BE poster_path ir backdrop_path)
-	title := r.FormValue("title")
-	overview := r.FormValue("overview")
-	releaseDateStr := r.FormValue("release_date")
-	voteAvgStr := r.FormValue("vote_average")
-	voteCountStr := r.FormValue("vote_count")
-	popularityStr := r.FormValue("popularity")
-	runtimeStr := r.FormValue("runtime")
-	status := r.FormValue("status")
-	imdbID := r.FormValue("imdb_id") // Tik IMDB ID
-
-	// Konvertuoti
-	var releaseDate *time.Time
-	if releaseDateStr != "" {
-		rd, _ := time.Parse("2006-01-02", releaseDateStr)
-		releaseDate = &rd
-	}
-
-	var voteAvg *float64
-	if voteAvgStr != "" {
-		va, _ := strconv.ParseFloat(voteAvgStr, 64)
-		voteAvg = &va
-	}
-
-	var voteCount *int
-	if voteCountStr != "" {
-		vc, _ := strconv.Atoi(voteCountStr)
-		voteCount = &vc
-	} else {
-		defaultVoteCount := 0
-		voteCount = &defaultVoteCount
-	}
-
-	var popularity *float64
-	if popularityStr != "" {
-		pop, _ := strconv.ParseFloat(popularityStr, 64)
-		popularity = &pop
-	} else {
-		defaultPopularity := 0.0
-		popularity = &defaultPopularity
-	}
-
-	var runtime *int
-	if runtimeStr != "" {
-		rt, _ := strconv.Atoi(runtimeStr)
-		runtime = &rt
-	} else {
-		defaultRuntime := 120
-		runtime = &defaultRuntime
+	// Tos pačios taisyklės kaip POST /api/v1/movies
+	v := validation.New()
+	req := movieRequestFromForm(r.Form, v)
+	req.validate(v, false)
+	if err := h.resolveGenres(r.Context(), v, req); err != nil {
+		log.Printf("Error loading genres: %v", err)
+		http.Error(w, "Failed to load genres", http.StatusInternalServerError)
+		return
 	}
-
-	if status == "" {
-		status = "Released"
+	if !v.Valid() {
+		h.renderCreateMovieForm(w, r, http.StatusUnprocessableEntity, v.Errors)
+		return
 	}
 
-	// Sukurti filmą (poster_path ir backdrop_path = nil)
+	// Numatytosios reikšmės tuštiems laukams (poster_path ir backdrop_path = nil)
+	status := "Released"
+	voteCount := 0
+	popularity := 0.0
+	runtime := 120
 	movie := &models.Movie{
-		Title:        title,
-		Overview:     stringPtr(overview),
-		ReleaseDate:  releaseDate,
-		PosterPath:   nil, // Nėra nuotraukų
-		BackdropPath: nil, // Nėra nuotraukų
-		VoteAverage:  voteAvg,
-		VoteCount:    voteCount,
-		Popularity:   popularity,
-		Runtime:      runtime,
-		Status:       &status,
-		ImdbID:       stringPtr(imdbID), // Tik IMDB ID
+		VoteCount:  &voteCount,
+		Popularity: &popularity,
+		Runtime:    &runtime,
+		Status:     &status,
 	}
+	req.apply(movie)
 
 	err := h.movieRepo.CreateMovie(r.Context(), movie)
+	if errors.Is(err, repository.ErrDuplicateImdbID) {
+		v.AddError("imdb_id", "is already used by another movie")
+		h.renderCreateMovieForm(w, r, http.StatusConflict, v.Errors)
+		return
+	}
 	if err != nil {
 		log.Printf("Error creating movie: %v", err)
 		http.Error(w, "Failed to create movie", http.StatusInternalServerError)
 		return
 	}
+	h.recordAudit(r, auditMovieCreate, "movie", movie.MovieID.String(), nil, movie)
 
 	http.Redirect(w, r, "/admin/movies", http.StatusSeeOther)
 }
 
+func (h *Handler) renderCreateMovieForm(w http.ResponseWriter, r *http.Request, status int, errs validation.Errors) {
+	email := h.sessionManager.GetString(r.Context(), "email")
+	role := h.sessionManager.GetString(r.Context(), "role")
+
+	form := templates.MovieFormFromValues(r.Form, errs)
+	form.GenreOptions = h.genreNames(r.Context())
+
+	w.WriteHeader(status)
+	component := templates.CreateMoviePage(email, role, form)
+	component.Render(r.Context(), w)
+}
+
 // ==================== FILMO REDAGAVIMAS ====================
 
 // HandleEditMoviePage - filmo redagavimo forma
@@ -193,7 +228,9 @@ func (h *Handler) HandleEditMoviePage(w http.ResponseWriter, r *http.Request) {
 	email := h.sessionManager.GetString(r.Context(), "email")
 	role := h.sessionManager.GetString(r.Context(), "role")
 
-	component := templates.EditMoviePage(email, role, *movie)
+	form := templates.MovieFormFromMovie(*movie)
+	form.GenreOptions = h.genreNames(r.Context())
+	component := templates.EditMoviePage(email, role, *movie, form)
 	component.Render(r.Context(), w)
 }
 
@@ -212,46 +249,36 @@ func (h *Handler) HandleUpdateMovie(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Gauti duomenis
-	title := r.FormValue("title")
-	overview := r.FormValue("overview")
-	releaseDateStr := r.FormValue("release_date")
-	voteAvgStr := r.FormValue("vote_average")
-	runtimeStr := r.FormValue("runtime")
-	status := r.FormValue("status")
-
-	// Konvertuoti
-	var releaseDate *time.Time
-	if releaseDateStr != "" {
-		rd, _ := time.Parse("2006-01-02", releaseDateStr)
-		releaseDate = &rd
-	}
-
-	var voteAvg *float64
-	if voteAvgStr != "" {
-		va, _ := strconv.ParseFloat(voteAvgStr, 64)
-		voteAvg = &va
+	before, err := h.movieRepo.GetMovieByID(r.Context(), movieID)
+	if err != nil {
+		http.Error(w, "Movie not found", http.StatusNotFound)
+		return
 	}
 
-	var runtime *int
-	if runtimeStr != "" {
-		rt, _ := strconv.Atoi(runtimeStr)
-		runtime = &rt
+	v := validation.New()
+	req := movieRequestFromForm(r.Form, v)
+	req.validate(v, false)
+	if err := h.resolveGenres(r.Context(), v, req); err != nil {
+		log.Printf("Error loading genres: %v", err)
+		http.Error(w, "Failed to load genres", http.StatusInternalServerError)
+		return
 	}
+	if !v.Valid() {
+		email := h.sessionManager.GetString(r.Context(), "email")
+		role := h.sessionManager.GetString(r.Context(), "role")
+		form := templates.MovieFormFromValues(r.Form, v.Errors)
+		form.GenreOptions = h.genreNames(r.Context())
 
-	if status == "" {
-		status = "Released"
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		component := templates.EditMoviePage(email, role, *before, form)
+		component.Render(r.Context(), w)
+		return
 	}
 
-	// Atnaujinti filmą
-	movie := &models.Movie{
-		Title:       title,
-		Overview:    stringPtr(overview),
-		ReleaseDate: releaseDate,
-		VoteAverage: voteAvg,
-		Runtime:     runtime,
-		Status:      &status,
-	}
+	// Atnaujinti filmą (UpdateMovie keičia tik formos laukus)
+	status := "Released"
+	movie := &models.Movie{Status: &status}
+	req.apply(movie)
 
 	err = h.movieRepo.UpdateMovie(r.Context(), movieID, movie)
 	if err != nil {
@@ -260,6 +287,13 @@ func (h *Handler) HandleUpdateMovie(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	after, err := h.movieRepo.GetMovieByID(r.Context(), movieID)
+	if err != nil {
+		log.Printf("Error reloading movie %s for audit: %v", movieID, err)
+		after = movie
+	}
+	h.recordAudit(r, auditMovieUpdate, "movie", movieID.String(), before, after)
+
 	http.Redirect(w, r, "/admin/movies", http.StatusSeeOther)
 }
 
@@ -284,6 +318,12 @@ func (h *Handler) HandleDeleteMovie(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	before, err := h.movieRepo.GetMovieByID(r.Context(), movieID)
+	if err != nil {
+		http.Error(w, "Movie not found", http.StatusNotFound)
+		return
+	}
+
 	// Ištrinti filmą
 	err = h.movieRepo.DeleteMovie(r.Context(), movieID)
 	if err != nil {
@@ -291,27 +331,11 @@ func (h *Handler) HandleDeleteMovie(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to delete movie", http.StatusInternalServerError)
 		return
 	}
+	h.recordAudit(r, auditMovieDelete, "movie", movieID.String(), before, nil)
 
 	http.Redirect(w, r, "/admin/movies", http.StatusSeeOther)
 }
 
-// ==================== API ENDPOINTS ====================
-
-func (h *Handler) HandleAPICreateMovie(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"status":"success", "message":"API endpoint for creating movies"}`))
-}
-
-func (h *Handler) HandleAPIUpdateMovie(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"status":"success", "message":"Movie updated"}`))
-}
-
-func (h *Handler) HandleAPIDeleteMovie(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"status":"success", "message":"Movie deleted"}`))
-}
-
 // ==================== MODERATOR HANDLERS ====================
 
 // HandleModeratorDashboard - moderator dashboard
@@ -331,14 +355,49 @@ func (h *Handler) HandleModeratorUsers(w http.ResponseWriter, r *http.Request) {
 	role := h.sessionManager.GetString(r.Context(), "role")
 	currentUserIDStr := h.sessionManager.GetString(r.Context(), "userID")
 
-	// Get all users
-	users, err := h.userRepo.GetAllUsers(r.Context(), 50, 0)
+	query := r.URL.Query()
+	list := templates.UserListView{
+		Query:         query.Get("q"),
+		Role:          query.Get("role"),
+		Status:        query.Get("status"),
+		Verified:      query.Get("verified"),
+		LastLoginFrom: query.Get("last_login_from"),
+		LastLoginTo:   query.Get("last_login_to"),
+		Page:          1,
+		TotalPages:    1,
+		Roles:         h.policy.Roles(),
+	}
+
+	filter, page, err := parseUserFilter(query)
+	list.Sort = filter.Sort
+	list.Order = "asc"
+	if filter.Descending {
+		list.Order = "desc"
+	}
 	if err != nil {
-		log.Printf("Error getting users: %v", err)
-		users = []models.User{}
+		list.Error = "Invalid filter: " + err.Error()
+	} else {
+		list.Page = page
+		list.Users, list.Total, err = h.userRepo.SearchUsers(r.Context(), filter)
+		if err != nil {
+			log.Printf("Error searching users: %v", err)
+			list.Error = "Failed to load users"
+		}
+		list.TotalPages = totalPages(list.Total, filter.Limit)
 	}
 
-	component := templates.ModeratorUsersPage(email, role, users, currentUserIDStr)
+	lockouts, err := h.loginAttemptRepo.GetAccountLockouts(r.Context())
+	if err != nil {
+		log.Printf("Error getting login lockouts: %v", err)
+		lockouts = map[string]time.Time{}
+	}
+
+	suspended, err := h.suspensionRepo.GetSuspendedUsers(r.Context())
+	if err != nil {
+		log.Printf("Error getting suspended users: %v", err)
+	}
+
+	component := templates.ModeratorUsersPage(email, role, list, currentUserIDStr, lockouts, h.assignableRoles(role), suspended)
 	component.Render(r.Context(), w)
 }
 
@@ -349,92 +408,128 @@ func (h *Handler) HandleModeratorUpdateRole(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	userIDStr := r.FormValue("user_id")
-	newRole := r.FormValue("new_role")
-	currentUserIDStr := h.sessionManager.GetString(r.Context(), "userID")
+	if _, merr := h.updateUserRole(r, r.FormValue("user_id"), r.FormValue("new_role")); merr != nil {
+		http.Error(w, merr.message, merr.status)
+		return
+	}
 
-	// Check if trying to change own role
-	if userIDStr == currentUserIDStr {
-		http.Error(w, "Cannot change your own role", http.StatusBadRequest)
+	http.Redirect(w, r, "/moderator/users", http.StatusSeeOther)
+}
+
+// HandleModeratorDeactivateUser - suspenduoja vartotoją su priežastimi ir (nebūtina) trukme
+func (h *Handler) HandleModeratorDeactivateUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+	reason := strings.TrimSpace(r.FormValue("reason"))
+	if _, merr := h.suspendUser(r, r.FormValue("user_id"), reason, r.FormValue("duration_days")); merr != nil {
+		http.Error(w, merr.message, merr.status)
 		return
 	}
 
-	// Validate role
-	validRoles := map[string]bool{"user": true, "moderator": true, "admin": true}
-	if !validRoles[newRole] {
-		http.Error(w, "Invalid role", http.StatusBadRequest)
+	http.Redirect(w, r, "/moderator/users", http.StatusSeeOther)
+}
+
+// ==================== MODERATOR API ENDPOINTS ====================
+
+// HandleAPIModeratorUsers - GET /api/v1/moderator/users (tie patys filtrai kaip /moderator/users)
+func (h *Handler) HandleAPIModeratorUsers(w http.ResponseWriter, r *http.Request) {
+	filter, page, err := parseUserFilter(r.URL.Query())
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, response.CodeBadRequest, err.Error())
 		return
 	}
 
-	// Update role
-	err = h.userRepo.UpdateUserRole(r.Context(), userID, newRole)
+	users, total, err := h.userRepo.SearchUsers(r.Context(), filter)
 	if err != nil {
-		log.Printf("Error updating user role: %v", err)
-		http.Error(w, "Failed to update user role", http.StatusInternalServerError)
+		log.Printf("Error searching users: %v", err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to load users")
 		return
 	}
+	if users == nil {
+		users = []models.User{}
+	}
 
-	http.Redirect(w, r, "/moderator/users", http.StatusSeeOther)
+	response.JSON(w, http.StatusOK, map[string]any{
+		"users":       users,
+		"total":       total,
+		"page":        page,
+		"per_page":    filter.Limit,
+		"total_pages": totalPages(total, filter.Limit),
+	})
 }
 
-// HandleModeratorDeactivateUser - deactivate user
-func (h *Handler) HandleModeratorDeactivateUser(w http.ResponseWriter, r *http.Request) {
-	if r.Method != "POST" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// HandleAPIModeratorUpdateRole - PUT /api/v1/moderator/users/{id}/role {"role": "..."}.
+// Grąžina atnaujintą vartotoją.
+func (h *Handler) HandleAPIModeratorUpdateRole(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Role string `json:"role"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.Error(w, r, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request body")
 		return
 	}
 
-	userIDStr := r.FormValue("user_id")
-	currentUserIDStr := h.sessionManager.GetString(r.Context(), "userID")
-
-	// Check if trying to deactivate self
-	if userIDStr == currentUserIDStr {
-		http.Error(w, "Cannot deactivate yourself", http.StatusForbidden)
+	user, merr := h.updateUserRole(r, chi.URLParam(r, "id"), request.Role)
+	if merr != nil {
+		response.Error(w, r, merr.status, merr.code, merr.message)
 		return
 	}
 
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+	response.JSON(w, http.StatusOK, user)
+}
+
+// HandleAPIModeratorDeactivateUser - DELETE /api/v1/moderator/users/{id}
+// {"reason": "...", "duration_days": 7} (0 arba nenurodyta - neterminuotai).
+// Grąžina suspenduotą vartotoją su suspensija.
+func (h *Handler) HandleAPIModeratorDeactivateUser(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Reason       string `json:"reason"`
+		DurationDays int    `json:"duration_days"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		response.Error(w, r, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request body")
 		return
 	}
 
-	// Deactivate user
-	err = h.userRepo.DeactivateUser(r.Context(), userID)
-	if err != nil {
-		log.Printf("Error deactivating user: %v", err)
-		http.Error(w, "Failed to deactivate user", http.StatusInternalServerError)
+	suspended, merr := h.suspendUser(r, chi.URLParam(r, "id"), strings.TrimSpace(request.Reason), strconv.Itoa(request.DurationDays))
+	if merr != nil {
+		response.Error(w, r, merr.status, merr.code, merr.message)
 		return
 	}
 
-	http.Redirect(w, r, "/moderator/users", http.StatusSeeOther)
+	response.JSON(w, http.StatusOK, suspended)
 }
 
-// ==================== MODERATOR API ENDPOINTS ====================
-
-func (h *Handler) HandleAPIModeratorUsers(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"status":"success", "message":"API endpoint for moderator users"}`))
-}
+// ==================== HELPER FUNCTIONS ====================
 
-func (h *Handler) HandleAPIModeratorUpdateRole(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"status":"success", "message":"User role updated"}`))
+// assignableRoles - rolės, kurias actorRole gali suteikti (ne aukštesnės už jo paties)
+func (h *Handler) assignableRoles(actorRole string) []string {
+	var roles []string
+	for _, role := range h.policy.Roles() {
+		if h.policy.Covers(actorRole, role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
 }
 
-func (h *Handler) HandleAPIModeratorDeactivateUser(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"status":"success", "message":"User deactivated"}`))
+// genreNames - visų žanrų pavadinimai formoms ir filtrams (klaidos atveju tuščias sąrašas)
+func (h *Handler) genreNames(ctx context.Context) []string {
+	genres, err := h.movieRepo.GetGenres(ctx)
+	if err != nil {
+		log.Printf("Error loading genres: %v", err)
+		return nil
+	}
+	names := make([]string, len(genres))
+	for i, genre := range genres {
+		names[i] = genre.Name
+	}
+	return names
 }
 
-// ==================== HELPER FUNCTIONS ====================
-
 // Helper function for string pointer
 func stringPtr(s string) *string {
 	return &s
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"battleNet/models"
+	"battleNet/repository"
+
+	"github.com/google/uuid"
+)
+
+// Prisijungimo ribojimas: po threshold nesėkmių subjektas blokuojamas
+// loginLockoutBase laikui, kiekviena kita nesėkmė blokuotę padvigubina.
+const (
+	accountLockoutThreshold = 5
+	ipLockoutThreshold      = 20
+	loginLockoutBase        = time.Minute
+	loginLockoutMax         = time.Hour
+	loginFailureWindow      = 24 * time.Hour
+)
+
+// loginLockedFor grąžina, kiek dar laiko paskyra ar IP užblokuoti (0 - neužblokuoti)
+func (h *Handler) loginLockedFor(ctx context.Context, email, ip string) time.Duration {
+	lockedUntil, err := h.loginAttemptRepo.LockedUntil(ctx, normalizeEmail(email), ip)
+	if err != nil {
+		log.Printf("Failed to check login lockout for %s: %v", email, err)
+		return 0
+	}
+	if lockedUntil.IsZero() {
+		return 0
+	}
+	return time.Until(lockedUntil)
+}
+
+// recordLoginFailure įrašo nesėkmingą bandymą ir, jei reikia, užblokuoja paskyrą ir IP
+func (h *Handler) recordLoginFailure(ctx context.Context, r *http.Request, email string, userID *uuid.UUID, reason string) {
+	ip := clientIP(r)
+	h.recordLoginAttempt(ctx, r, email, userID, false, reason)
+
+	h.registerLoginFailure(ctx, repository.LockoutScopeAccount, normalizeEmail(email), accountLockoutThreshold)
+	h.registerLoginFailure(ctx, repository.LockoutScopeIP, ip, ipLockoutThreshold)
+}
+
+// recordLoginSuccess įrašo sėkmingą bandymą ir nunulina paskyros nesėkmes.
+// IP skaitliukas nenulinamas - kitaip prisijungimas prie savo paskyros leistų
+// toliau spėlioti kitų slaptažodžius.
+func (h *Handler) recordLoginSuccess(ctx context.Context, r *http.Request, user *models.User) {
+	h.recordLoginAttempt(ctx, r, user.Email, &user.UserID, true, "")
+
+	if err := h.loginAttemptRepo.Clear(ctx, repository.LockoutScopeAccount, normalizeEmail(user.Email)); err != nil {
+		log.Printf("Failed to clear login failures for %s: %v", user.Email, err)
+	}
+}
+
+func (h *Handler) recordLoginAttempt(ctx context.Context, r *http.Request, email string, userID *uuid.UUID, success bool, reason string) {
+	err := h.loginAttemptRepo.RecordAttempt(ctx, models.LoginAttempt{
+		Email:     normalizeEmail(email),
+		UserID:    userID,
+		IPAddress: clientIP(r),
+		UserAgent: r.UserAgent(),
+		Success:   success,
+		Reason:    reason,
+	})
+	if err != nil {
+		log.Printf("Failed to record login attempt for %s: %v", email, err)
+	}
+}
+
+func (h *Handler) registerLoginFailure(ctx context.Context, scope, subject string, threshold int) {
+	if subject == "" {
+		return
+	}
+
+	failures, err := h.loginAttemptRepo.RegisterFailure(ctx, scope, subject, loginFailureWindow)
+	if err != nil {
+		log.Printf("Failed to register login failure for %s %s: %v", scope, subject, err)
+		return
+	}
+	if failures < threshold {
+		return
+	}
+
+	duration := lockoutDuration(failures - threshold)
+	if err := h.loginAttemptRepo.Lock(ctx, scope, subject, time.Now().Add(duration)); err != nil {
+		log.Printf("Failed to lock %s %s: %v", scope, subject, err)
+		return
+	}
+	log.Printf("🔒 Login locked for %s %s for %s after %d failures", scope, subject, duration, failures)
+}
+
+// lockoutDuration - eksponentinis atidėjimas: 1, 2, 4, ... min., ne daugiau nei loginLockoutMax
+func lockoutDuration(excess int) time.Duration {
+	return min(loginLockoutBase<<min(excess, 10), loginLockoutMax)
+}
+
+// lockoutMessage - žmogui suprantamas pranešimas apie blokuotę
+func lockoutMessage(remaining time.Duration) string {
+	minutes := int(math.Ceil(remaining.Minutes()))
+	if minutes <= 1 {
+		return "Too many failed login attempts. Please try again in a minute."
+	}
+	return fmt.Sprintf("Too many failed login attempts. Please try again in %d minutes.", minutes)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// HandleModeratorUnlockUser - moderatorius panaikina paskyros prisijungimo blokuotę
+func (h *Handler) HandleModeratorUnlockUser(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	email := normalizeEmail(r.FormValue("email"))
+	if email == "" {
+		http.Error(w, "Email required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.loginAttemptRepo.Clear(r.Context(), repository.LockoutScopeAccount, email); err != nil {
+		log.Printf("Error unlocking user %s: %v", email, err)
+		http.Error(w, "Failed to unlock user", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Moderator %s unlocked login for %s", h.sessionManager.GetString(r.Context(), "email"), email)
+	h.recordAudit(r, auditUserUnlock, "user", email, nil, nil)
+	http.Redirect(w, r, "/moderator/users", http.StatusSeeOther)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"battleNet/internal/response"
+	"battleNet/middlewaree"
+	"battleNet/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// moderationError - moderatoriaus veiksmo klaida su HTTP statusu. Ją
+// atvaizduoja kviečiantis handler'is: HTML puslapiui tekstu, API - JSON.
+type moderationError struct {
+	status  int
+	code    string // response.Code* API atsakymui
+	message string
+}
+
+func (e *moderationError) Error() string {
+	return e.message
+}
+
+// moderationCodes - API klaidos kodas pagal statusą
+var moderationCodes = map[int]string{
+	http.StatusBadRequest:          response.CodeBadRequest,
+	http.StatusUnauthorized:        response.CodeUnauthorized,
+	http.StatusForbidden:           response.CodeForbidden,
+	http.StatusNotFound:            response.CodeNotFound,
+	http.StatusInternalServerError: response.CodeInternal,
+}
+
+func moderationFailed(status int, message string) *moderationError {
+	return &moderationError{status: status, code: moderationCodes[status], message: message}
+}
+
+// moderationTarget nuskaito vartotoją, su kuriuo atliekamas veiksmas, ir patikrina,
+// kad tai ne pats moderatorius ir kad jo rolė nėra aukštesnė
+func (h *Handler) moderationTarget(r *http.Request, targetIDStr, selfMessage, higherMessage string) (*models.User, *moderationError) {
+	if targetIDStr == h.contextString(r, middlewaree.UserIDKey, "userID") {
+		return nil, moderationFailed(http.StatusForbidden, selfMessage)
+	}
+
+	targetID, err := uuid.Parse(targetIDStr)
+	if err != nil {
+		return nil, moderationFailed(http.StatusBadRequest, "Invalid user ID")
+	}
+
+	target, err := h.userRepo.GetUserByID(r.Context(), targetID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, moderationFailed(http.StatusNotFound, "User not found")
+	}
+	if err != nil {
+		log.Printf("Error getting user %s: %v", targetID, err)
+		return nil, moderationFailed(http.StatusInternalServerError, "Failed to load user")
+	}
+
+	if !h.policy.Covers(h.contextString(r, middlewaree.RoleKey, "role"), target.Role) {
+		return nil, moderationFailed(http.StatusForbidden, higherMessage)
+	}
+	return target, nil
+}
+
+// updateUserRole pakeičia vartotojo rolę. Suteikti galima tik rolę, kurios
+// leidimus turi pats moderatorius, ir tik vartotojui, kurio rolė nėra aukštesnė.
+// Vartotojas atjungiamas visuose įrenginiuose, kad nauja rolė galiotų iškart.
+func (h *Handler) updateUserRole(r *http.Request, targetIDStr, newRole string) (*models.User, *moderationError) {
+	target, merr := h.moderationTarget(r, targetIDStr, "Cannot change your own role", "You can't change the role of a user with a higher role")
+	if merr != nil {
+		return nil, merr
+	}
+
+	if !h.policy.Exists(newRole) {
+		return nil, moderationFailed(http.StatusBadRequest, "Invalid role")
+	}
+	if !h.policy.Covers(h.contextString(r, middlewaree.RoleKey, "role"), newRole) {
+		return nil, moderationFailed(http.StatusForbidden, "You can't assign this role")
+	}
+
+	if err := h.userRepo.UpdateUserRole(r.Context(), target.UserID, newRole); err != nil {
+		log.Printf("Error updating user role: %v", err)
+		return nil, moderationFailed(http.StatusInternalServerError, "Failed to update user role")
+	}
+	h.recordAudit(r, auditUserRoleUpdate, "user", target.UserID.String(),
+		map[string]string{"role": target.Role}, map[string]string{"role": newRole})
+
+	// Rolė saugoma sesijoje, todėl senos sesijos turėtų senus leidimus - atjungiam
+	if _, err := h.sessionStore.DeleteByUser(r.Context(), target.UserID, ""); err != nil {
+		log.Printf("Error deleting sessions after role update of user %s: %v", target.UserID, err)
+	}
+	if err := h.apiSessionRepo.RevokeAllForUser(r.Context(), target.UserID); err != nil {
+		log.Printf("Error revoking API sessions after role update of user %s: %v", target.UserID, err)
+	}
+
+	updated, err := h.userRepo.GetUserByID(r.Context(), target.UserID)
+	if err != nil {
+		log.Printf("Error reloading user %s after role update: %v", target.UserID, err)
+		target.Role = newRole
+		return target, nil
+	}
+	return updated, nil
+}
+
+// suspendUser suspenduoja vartotoją ir atjungia visas jo sesijas.
+// durationDays - kaip parseSuspensionExpiry ("" arba "0" - neterminuotai).
+func (h *Handler) suspendUser(r *http.Request, targetIDStr, reason, durationDays string) (*models.SuspendedUser, *moderationError) {
+	moderatorID, err := uuid.Parse(h.contextString(r, middlewaree.UserIDKey, "userID"))
+	if err != nil {
+		return nil, moderationFailed(http.StatusUnauthorized, "Invalid session")
+	}
+
+	if reason == "" || len(reason) > maxSuspensionReasonLength {
+		return nil, moderationFailed(http.StatusBadRequest, "A suspension reason (up to 500 characters) is required")
+	}
+	expiresAt, err := parseSuspensionExpiry(durationDays)
+	if err != nil {
+		return nil, moderationFailed(http.StatusBadRequest, "Invalid suspension duration")
+	}
+
+	target, merr := h.moderationTarget(r, targetIDStr, "Cannot deactivate yourself", "You can't deactivate a user with a higher role")
+	if merr != nil {
+		return nil, merr
+	}
+
+	if err := h.suspensionRepo.Suspend(r.Context(), target.UserID, reason, moderatorID, expiresAt); err != nil {
+		log.Printf("Error deactivating user: %v", err)
+		return nil, moderationFailed(http.StatusInternalServerError, "Failed to deactivate user")
+	}
+	h.recordAudit(r, auditUserDeactivate, "user", target.UserID.String(),
+		map[string]any{"is_active": target.IsActive},
+		map[string]any{"is_active": false, "suspension_reason": reason, "suspension_expires_at": expiresAt})
+
+	// Iškart atjungti vartotoją visuose įrenginiuose
+	if _, err := h.sessionStore.DeleteByUser(r.Context(), target.UserID, ""); err != nil {
+		log.Printf("Error deleting sessions for deactivated user %s: %v", target.UserID, err)
+	}
+	if err := h.apiSessionRepo.RevokeAllForUser(r.Context(), target.UserID); err != nil {
+		log.Printf("Error revoking API sessions for deactivated user %s: %v", target.UserID, err)
+	}
+
+	suspended, err := h.suspensionRepo.GetSuspendedUser(r.Context(), target.UserID)
+	if err != nil {
+		log.Printf("Error reloading suspended user %s: %v", target.UserID, err)
+		return nil, moderationFailed(http.StatusInternalServerError, "User suspended, but failed to load the result")
+	}
+	suspended.User.PasswordHash = ""
+	return suspended, nil
+}
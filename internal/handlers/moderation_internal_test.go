@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"battleNet/internal/response"
+	"battleNet/middlewaree"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Validacijos ir „pats sau" patikros grąžina klaidą dar prieš kreipiantis į DB,
+// todėl šie testai veikia su tuščiu Handler'iu, be TEST_DATABASE_URL.
+
+const testModeratorID = "11111111-1111-1111-1111-111111111111"
+
+// moderatorRequest - API užklausa, lyg ją būtų praleidęs RequireAuthAPI su moderatoriaus principal'u
+func moderatorRequest(method, targetID, body string) *http.Request {
+	req := httptest.NewRequest(method, "/api/v1/moderator/users/"+targetID, strings.NewReader(body))
+	routeCtx := chi.NewRouteContext()
+	routeCtx.URLParams.Add("id", targetID)
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, routeCtx)
+	ctx = context.WithValue(ctx, middlewaree.UserIDKey, testModeratorID)
+	ctx = context.WithValue(ctx, middlewaree.RoleKey, "moderator")
+	return req.WithContext(ctx)
+}
+
+func TestModeratorAPIValidation(t *testing.T) {
+	h := &Handler{}
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		target  string
+		body    string
+		status  int
+		code    string
+	}{
+		{"role: invalid body", h.HandleAPIModeratorUpdateRole, http.MethodPut, "not-a-uuid", `{"role":`, http.StatusBadRequest, response.CodeInvalidBody},
+		{"role: invalid user ID", h.HandleAPIModeratorUpdateRole, http.MethodPut, "not-a-uuid", `{"role":"user"}`, http.StatusBadRequest, response.CodeBadRequest},
+		{"role: own account", h.HandleAPIModeratorUpdateRole, http.MethodPut, testModeratorID, `{"role":"admin"}`, http.StatusForbidden, response.CodeForbidden},
+		{"suspend: invalid body", h.HandleAPIModeratorDeactivateUser, http.MethodDelete, "not-a-uuid", `{"reason":1}`, http.StatusBadRequest, response.CodeInvalidBody},
+		{"suspend: missing reason", h.HandleAPIModeratorDeactivateUser, http.MethodDelete, "not-a-uuid", `{"reason":"  "}`, http.StatusBadRequest, response.CodeBadRequest},
+		{"suspend: reason too long", h.HandleAPIModeratorDeactivateUser, http.MethodDelete, "not-a-uuid", `{"reason":"` + strings.Repeat("x", maxSuspensionReasonLength+1) + `"}`, http.StatusBadRequest, response.CodeBadRequest},
+		{"suspend: negative duration", h.HandleAPIModeratorDeactivateUser, http.MethodDelete, "not-a-uuid", `{"reason":"spam","duration_days":-1}`, http.StatusBadRequest, response.CodeBadRequest},
+		{"suspend: duration too long", h.HandleAPIModeratorDeactivateUser, http.MethodDelete, "not-a-uuid", `{"reason":"spam","duration_days":3651}`, http.StatusBadRequest, response.CodeBadRequest},
+		{"suspend: invalid user ID", h.HandleAPIModeratorDeactivateUser, http.MethodDelete, "not-a-uuid", `{"reason":"spam"}`, http.StatusBadRequest, response.CodeBadRequest},
+		{"suspend: own account", h.HandleAPIModeratorDeactivateUser, http.MethodDelete, testModeratorID, `{"reason":"spam"}`, http.StatusForbidden, response.CodeForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			tt.handler(rec, moderatorRequest(tt.method, tt.target, tt.body))
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			var problem struct {
+				Code string `json:"code"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("decode problem: %v", err)
+			}
+			if problem.Code != tt.code {
+				t.Errorf("code = %q, want %q", problem.Code, tt.code)
+			}
+		})
+	}
+}
+
+func TestParseSuspensionExpiry(t *testing.T) {
+	for _, days := range []string{"", "0"} {
+		expiresAt, err := parseSuspensionExpiry(days)
+		if err != nil || expiresAt != nil {
+			t.Errorf("parseSuspensionExpiry(%q) = %v, %v; want a permanent suspension", days, expiresAt, err)
+		}
+	}
+	for _, days := range []string{"7", "3650"} {
+		if expiresAt, err := parseSuspensionExpiry(days); err != nil || expiresAt == nil {
+			t.Errorf("parseSuspensionExpiry(%q) = %v, %v; want an expiry", days, expiresAt, err)
+		}
+	}
+	for _, days := range []string{"-1", "3651", "week", "1.5"} {
+		if _, err := parseSuspensionExpiry(days); err == nil {
+			t.Errorf("parseSuspensionExpiry(%q): want an error", days)
+		}
+	}
+}
@@ -0,0 +1,435 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"battleNet/config"
+	"battleNet/internal/handlers"
+	"battleNet/internal/router"
+	"battleNet/mail"
+	"battleNet/models"
+	"battleNet/repository"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Integraciniai moderatoriaus API testai. Reikia goose migracijomis paruoštos
+// DB: TEST_DATABASE_URL=postgres://... go test ./internal/handlers
+// Be TEST_DATABASE_URL testai praleidžiami.
+
+const testPassword = "correct-horse-battery"
+
+type testApp struct {
+	server *httptest.Server
+	users  *repository.UserRepository
+	audit  *repository.AuditRepository
+	db     *repository.Database
+}
+
+func newTestApp(t *testing.T) *testApp {
+	t.Helper()
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := repository.NewDatabase(databaseURL)
+	if err != nil {
+		t.Fatalf("connect to test database: %v", err)
+	}
+	t.Cleanup(db.Close)
+
+	policy, err := repository.NewRoleRepository(db.Pool).LoadPolicy(context.Background())
+	if err != nil {
+		t.Fatalf("load policy: %v", err)
+	}
+
+	sessionStore := repository.NewSessionStore(db.Pool, 0)
+	sessionManager := scs.New()
+	sessionManager.Store = sessionStore
+
+	repos := repository.NewRepositories(db.Pool, 0)
+	app := &testApp{
+		users: repos.Users,
+		audit: repos.Audit,
+		db:    db,
+	}
+	handler := handlers.NewHandler(handlers.Deps{
+		Repos:          repos,
+		SessionManager: sessionManager,
+		SessionStore:   sessionStore,
+		Policy:         policy,
+		JWTSecret:      "test-jwt-secret",
+		TokenSecret:    "test-token-secret",
+		AppBaseURL:     "http://localhost",
+		Mailer:         mail.NewFileMailer(t.TempDir(), "test@example.test"),
+	})
+
+	app.server = httptest.NewServer(router.New(handler, sessionManager, policy, &config.Config{}))
+	t.Cleanup(app.server.Close)
+	return app
+}
+
+// createUser sukuria patvirtintą vartotoją su nurodyta role ir testPassword slaptažodžiu
+func (app *testApp) createUser(t *testing.T, role string) *models.User {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	suffix := uuid.NewString()[:8]
+	user := &models.User{
+		Email:         role + "-" + suffix + "@example.test",
+		PasswordHash:  string(hash),
+		FirstName:     "Test",
+		LastName:      role,
+		Username:      role + "_" + suffix,
+		Role:          role,
+		EmailVerified: true,
+	}
+	if err := app.users.CreateUser(context.Background(), user); err != nil {
+		t.Fatalf("create %s: %v", role, err)
+	}
+	// audit_event yra append-only (migracija 00013), todėl įvykiai lieka DB
+	t.Cleanup(func() {
+		if _, err := app.db.Pool.Exec(context.Background(), `DELETE FROM "user" WHERE user_id = $1`, user.UserID); err != nil {
+			t.Errorf("delete %s: %v", user.Email, err)
+		}
+	})
+	return user
+}
+
+// apiToken prisijungia per POST /api/v1/auth/token ir grąžina access token'ą
+func (app *testApp) apiToken(t *testing.T, user *models.User) string {
+	t.Helper()
+	status, body := app.apiRequest(t, http.MethodPost, "/api/v1/auth/token", "",
+		map[string]string{"email": user.Email, "password": testPassword})
+	if status != http.StatusOK {
+		t.Fatalf("token for %s: status %d: %s", user.Email, status, body)
+	}
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &tokens); err != nil {
+		t.Fatal(err)
+	}
+	return tokens.AccessToken
+}
+
+func (app *testApp) apiRequest(t *testing.T, method, path, token string, payload any) (int, []byte) {
+	t.Helper()
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, app.server.URL+path, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := app.server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, data
+}
+
+// auditEvents - actor įvykiai su action, kurių taikinys target
+func (app *testApp) auditEvents(t *testing.T, actor *models.User, action string, target uuid.UUID) []models.AuditEvent {
+	t.Helper()
+	events, err := app.audit.GetEvents(context.Background(), models.AuditFilter{Actor: actor.UserID.String(), Action: action, Limit: 50})
+	if err != nil {
+		t.Fatalf("load audit events: %v", err)
+	}
+	var matching []models.AuditEvent
+	for _, event := range events {
+		if event.TargetID == target.String() {
+			matching = append(matching, event)
+		}
+	}
+	return matching
+}
+
+var csrfInput = regexp.MustCompile(`name="csrf_token" value="([^"]+)"`)
+
+// webClient - naršyklės imitacija: slapukai ir CSRF token'as iš formų, be redirect'ų sekimo
+type webClient struct {
+	t      *testing.T
+	app    *testApp
+	client *http.Client
+}
+
+// client - naujas, dar neprisijungęs webClient
+func (app *testApp) client(t *testing.T) *webClient {
+	t.Helper()
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &webClient{t: t, app: app, client: &http.Client{
+		Jar:           jar,
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}}
+}
+
+func (app *testApp) login(t *testing.T, user *models.User) *webClient {
+	t.Helper()
+	c := app.client(t)
+	resp := c.postForm("/login", "/login", url.Values{"email": {user.Email}, "password": {testPassword}})
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("login %s: status %d", user.Email, resp.StatusCode)
+	}
+	return c
+}
+
+// get grąžina GET užklausos statusą
+func (c *webClient) get(path string) int {
+	c.t.Helper()
+	resp, err := c.client.Get(c.app.server.URL + path)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+// postForm atidaro formPage, paima jo CSRF token'ą ir siunčia formą į path
+func (c *webClient) postForm(formPage, path string, form url.Values) *http.Response {
+	c.t.Helper()
+	resp, err := c.client.Get(c.app.server.URL + formPage)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	match := csrfInput.FindSubmatch(page)
+	if match == nil {
+		c.t.Fatalf("no CSRF token on %s (status %d)", formPage, resp.StatusCode)
+	}
+
+	form.Set("csrf_token", string(match[1]))
+	resp, err = c.client.Post(c.app.server.URL+path, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+func TestModeratorAPIUpdateRole(t *testing.T) {
+	app := newTestApp(t)
+	moderator := app.createUser(t, "moderator")
+	target := app.createUser(t, "user")
+	token := app.apiToken(t, moderator)
+
+	status, body := app.apiRequest(t, http.MethodPut, "/api/v1/moderator/users/"+target.UserID.String()+"/role", token,
+		map[string]string{"role": "moderator"})
+	if status != http.StatusOK {
+		t.Fatalf("update role: status %d: %s", status, body)
+	}
+	var updated models.User
+	if err := json.Unmarshal(body, &updated); err != nil {
+		t.Fatal(err)
+	}
+	if updated.UserID != target.UserID || updated.Role != "moderator" {
+		t.Errorf("response user = %s with role %q, want %s with role moderator", updated.UserID, updated.Role, target.UserID)
+	}
+
+	stored, err := app.users.GetUserByID(context.Background(), target.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Role != "moderator" {
+		t.Errorf("stored role = %q, want moderator", stored.Role)
+	}
+
+	events := app.auditEvents(t, moderator, "user.role.update", target.UserID)
+	if len(events) != 1 {
+		t.Fatalf("got %d user.role.update audit events, want 1", len(events))
+	}
+	var changes map[string]struct {
+		Before string `json:"before"`
+		After  string `json:"after"`
+	}
+	if err := json.Unmarshal(events[0].Changes, &changes); err != nil {
+		t.Fatalf("audit changes: %v", err)
+	}
+	if changes["role"].Before != "user" || changes["role"].After != "moderator" {
+		t.Errorf("audit changes = %s, want role user -> moderator", events[0].Changes)
+	}
+}
+
+func TestModeratorAPIUpdateRoleErrors(t *testing.T) {
+	app := newTestApp(t)
+	moderator := app.createUser(t, "moderator")
+	admin := app.createUser(t, "admin")
+	target := app.createUser(t, "user")
+	token := app.apiToken(t, moderator)
+
+	tests := []struct {
+		name   string
+		userID string
+		role   string
+		want   int
+	}{
+		{"own role", moderator.UserID.String(), "user", http.StatusForbidden},
+		{"unknown role", target.UserID.String(), "superuser", http.StatusBadRequest},
+		{"role above own", target.UserID.String(), "admin", http.StatusForbidden},
+		{"user with higher role", admin.UserID.String(), "user", http.StatusForbidden},
+		{"invalid id", "not-a-uuid", "user", http.StatusBadRequest},
+		{"unknown user", uuid.NewString(), "user", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := app.apiRequest(t, http.MethodPut, "/api/v1/moderator/users/"+tt.userID+"/role", token,
+				map[string]string{"role": tt.role})
+			if status != tt.want {
+				t.Errorf("status = %d, want %d: %s", status, tt.want, body)
+			}
+		})
+	}
+
+	if events := app.auditEvents(t, moderator, "user.role.update", target.UserID); len(events) != 0 {
+		t.Errorf("rejected role changes wrote %d audit events", len(events))
+	}
+}
+
+func TestModeratorAPIRequiresModerator(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser(t, "user")
+	target := app.createUser(t, "user")
+	token := app.apiToken(t, user)
+
+	requests := []struct {
+		method, path string
+		payload      any
+	}{
+		{http.MethodGet, "/api/v1/moderator/users", nil},
+		{http.MethodPut, "/api/v1/moderator/users/" + target.UserID.String() + "/role", map[string]string{"role": "moderator"}},
+		{http.MethodDelete, "/api/v1/moderator/users/" + target.UserID.String(), map[string]string{"reason": "spam"}},
+	}
+	for _, req := range requests {
+		status, body := app.apiRequest(t, req.method, req.path, token, req.payload)
+		if status != http.StatusForbidden {
+			t.Errorf("%s %s: status = %d, want 403: %s", req.method, req.path, status, body)
+		}
+	}
+
+	stored, err := app.users.GetUserByID(context.Background(), target.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Role != "user" || !stored.IsActive {
+		t.Errorf("target changed by a non-moderator: role %q, active %v", stored.Role, stored.IsActive)
+	}
+}
+
+func TestModeratorSuspendAndUnsuspend(t *testing.T) {
+	app := newTestApp(t)
+	moderator := app.createUser(t, "moderator")
+	target := app.createUser(t, "user")
+	token := app.apiToken(t, moderator)
+	targetToken := app.apiToken(t, target)
+
+	status, body := app.apiRequest(t, http.MethodDelete, "/api/v1/moderator/users/"+target.UserID.String(), token,
+		map[string]any{"reason": "Spam reviews", "duration_days": 7})
+	if status != http.StatusOK {
+		t.Fatalf("suspend: status %d: %s", status, body)
+	}
+	var suspended models.SuspendedUser
+	if err := json.Unmarshal(body, &suspended); err != nil {
+		t.Fatal(err)
+	}
+	if suspended.User.UserID != target.UserID || suspended.User.IsActive {
+		t.Errorf("response user = %s active %v, want %s inactive", suspended.User.UserID, suspended.User.IsActive, target.UserID)
+	}
+	if suspended.Suspension.Reason != "Spam reviews" {
+		t.Errorf("suspension reason = %q", suspended.Suspension.Reason)
+	}
+	if expires := suspended.Suspension.ExpiresAt; expires == nil || time.Until(*expires) < 6*24*time.Hour {
+		t.Errorf("suspension expires at %v, want about 7 days from now", expires)
+	}
+	if len(app.auditEvents(t, moderator, "user.deactivate", target.UserID)) != 1 {
+		t.Error("suspension wasn't written to the audit log")
+	}
+
+	// Suspenduotas vartotojas atjungiamas ir naujo token'o negauna
+	if status, _ := app.apiRequest(t, http.MethodGet, "/api/v1/watchlist", targetToken, nil); status != http.StatusUnauthorized {
+		t.Errorf("suspended user's access token: status = %d, want 401", status)
+	}
+	if status, _ := app.apiRequest(t, http.MethodPost, "/api/v1/auth/token", "",
+		map[string]string{"email": target.Email, "password": testPassword}); status != http.StatusForbidden {
+		t.Errorf("suspended user's token request: status = %d, want 403", status)
+	}
+
+	// Suspensija nuimama moderatoriaus puslapyje
+	web := app.login(t, moderator)
+	resp := web.postForm("/moderator/users", "/moderator/users/reactivate", url.Values{"user_id": {target.UserID.String()}})
+	if resp.StatusCode != http.StatusSeeOther {
+		t.Fatalf("reactivate: status %d", resp.StatusCode)
+	}
+
+	stored, err := app.users.GetUserByID(context.Background(), target.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.IsActive {
+		t.Error("user is still inactive after reactivation")
+	}
+	if len(app.auditEvents(t, moderator, "user.reactivate", target.UserID)) != 1 {
+		t.Error("reactivation wasn't written to the audit log")
+	}
+	app.apiToken(t, target)
+}
+
+func TestModeratorAPISuspendErrors(t *testing.T) {
+	app := newTestApp(t)
+	moderator := app.createUser(t, "moderator")
+	admin := app.createUser(t, "admin")
+	target := app.createUser(t, "user")
+	token := app.apiToken(t, moderator)
+
+	tests := []struct {
+		name    string
+		userID  string
+		payload map[string]any
+		want    int
+	}{
+		{"missing reason", target.UserID.String(), map[string]any{}, http.StatusBadRequest},
+		{"invalid duration", target.UserID.String(), map[string]any{"reason": "spam", "duration_days": -1}, http.StatusBadRequest},
+		{"self", moderator.UserID.String(), map[string]any{"reason": "spam"}, http.StatusForbidden},
+		{"higher role", admin.UserID.String(), map[string]any{"reason": "spam"}, http.StatusForbidden},
+		{"unknown user", uuid.NewString(), map[string]any{"reason": "spam"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := app.apiRequest(t, http.MethodDelete, "/api/v1/moderator/users/"+tt.userID, token, tt.payload)
+			if status != tt.want {
+				t.Errorf("status = %d, want %d: %s", status, tt.want, body)
+			}
+		})
+	}
+}
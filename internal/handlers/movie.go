@@ -1,11 +1,10 @@
 package handlers
 
 import (
-	"encoding/json"
 	"log"
 	"net/http"
-	"strconv"
 
+	"battleNet/internal/response"
 	"battleNet/models"
 	"battleNet/templates"
 
@@ -38,7 +37,38 @@ func (h *Handler) HandleProfile(w http.ResponseWriter, r *http.Request) {
 	role := h.sessionManager.GetString(r.Context(), "role")
 	username := h.sessionManager.GetString(r.Context(), "username")
 
-	component := templates.ProfilePage(email, name, role, username)
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil {
+		http.Error(w, "Invalid session", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error getting user: %v", err)
+		http.Error(w, "Failed to load profile", http.StatusInternalServerError)
+		return
+	}
+
+	identities, err := h.oauthRepo.GetUserOAuths(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error getting linked identities: %v", err)
+		identities = []models.OAuth{}
+	}
+
+	flash := templates.Flash{
+		Success: h.sessionManager.PopString(r.Context(), "flash_success"),
+		Error:   h.sessionManager.PopString(r.Context(), "flash_error"),
+	}
+
+	// Sesija atnaujinama, jei email buvo patvirtintas kitame naršyklės lange
+	h.sessionManager.Put(r.Context(), "email_verified", user.EmailVerified)
+
+	component := templates.ProfilePage(email, name, role, username, user.EmailVerified, templates.LinkedAccounts{
+		Identities:  identities,
+		Providers:   h.oauthProviderList(),
+		HasPassword: user.PasswordHash != "",
+	}, flash)
 	component.Render(r.Context(), w)
 }
 
@@ -47,26 +77,56 @@ func (h *Handler) HandleMovies(w http.ResponseWriter, r *http.Request) {
 	email := h.sessionManager.GetString(r.Context(), "email")
 	role := h.sessionManager.GetString(r.Context(), "role")
 
-	// Get pagination parameters
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
+	query := r.URL.Query()
+	filter, page, errs := parseMovieFilter(query)
+
+	list := templates.MovieListView{
+		Query:      filter.Query,
+		Genres:     filter.Genres,
+		Statuses:   filter.Statuses,
+		YearFrom:   query.Get("year_from"),
+		YearTo:     query.Get("year_to"),
+		RuntimeMin: query.Get("runtime_min"),
+		RuntimeMax: query.Get("runtime_max"),
+		MinVote:    query.Get("min_vote"),
+		MinRating:  query.Get("min_rating"),
+		Sort:       query.Get("sort"),
+		Order:      query.Get("order"),
+		Errors:     errs,
+		Limit:      filter.Limit,
+		Page:       page,
+		TotalPages: 1,
 	}
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit < 1 || limit > 100 {
-		limit = 20
+
+	if errs == nil {
+		movies, total, err := h.movieRepo.ListMovies(r.Context(), filter)
+		if err != nil {
+			log.Printf("Error getting movies: %v", err)
+			http.Error(w, "Failed to load movies", http.StatusInternalServerError)
+			return
+		}
+		list.Movies = movies
+		list.Total = total
+		list.TotalPages = totalPages(total, filter.Limit)
+	} else {
+		// Facet'ai vis tiek rodomi - skaičiuojami be filtrų
+		filter = models.MovieFilter{}
 	}
-	offset := (page - 1) * limit
 
-	// Get movies from database
-	movies, err := h.movieRepo.GetMovies(r.Context(), int32(limit), int32(offset))
+	facets, err := h.movieRepo.MovieFacets(r.Context(), filter)
 	if err != nil {
-		log.Printf("Error getting movies: %v", err)
-		http.Error(w, "Failed to load movies", http.StatusInternalServerError)
+		log.Printf("Error counting movie facets: %v", err)
+	}
+	list.Facets = facets
+
+	// HTMX forma keičia tik rezultatus ir facet'ų skaičius
+	if r.Header.Get("HX-Request") == "true" && r.Header.Get("HX-Target") == "movie-results" {
+		templates.MovieResults(role, list).Render(r.Context(), w)
+		templates.MovieFacetsPanel(list, true).Render(r.Context(), w)
 		return
 	}
 
-	component := templates.MoviesPage(email, role, movies)
+	component := templates.MoviesPage(email, role, list)
 	component.Render(r.Context(), w)
 }
 
@@ -89,6 +149,11 @@ func (h *Handler) HandleMovieDetail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	credits, err := h.personRepo.GetMovieCredits(r.Context(), movieID)
+	if err != nil {
+		log.Printf("Error getting credits of movie %s: %v", movieID, err)
+	}
+
 	// Get reviews for this movie
 	reviews, err := h.reviewRepo.GetMovieReviews(r.Context(), movieID)
 	if err != nil {
@@ -105,36 +170,48 @@ func (h *Handler) HandleMovieDetail(w http.ResponseWriter, r *http.Request) {
 		inWatchlist, _ = h.watchlistRepo.CheckWatchlist(r.Context(), userID, movieID)
 	}
 
-	component := templates.MovieDetailPage(email, role, *movie, reviews, inWatchlist)
+	component := templates.MovieDetailPage(email, role, *movie, credits, reviews, inWatchlist)
 	component.Render(r.Context(), w)
 }
 
 // HandleAPIMovies returns movies as JSON (API endpoint)
 func (h *Handler) HandleAPIMovies(w http.ResponseWriter, r *http.Request) {
-	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
-	if page < 1 {
-		page = 1
-	}
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	if limit < 1 || limit > 100 {
-		limit = 20
+	filter, page, errs := parseMovieFilter(r.URL.Query())
+	if errs != nil {
+		response.Write(w, r, &response.Problem{
+			Status: http.StatusBadRequest,
+			Code:   response.CodeBadRequest,
+			Detail: "Invalid filter",
+			Errors: errs,
+		})
+		return
 	}
-	offset := (page - 1) * limit
 
-	movies, err := h.movieRepo.GetMovies(r.Context(), int32(limit), int32(offset))
+	movies, total, err := h.movieRepo.ListMovies(r.Context(), filter)
 	if err != nil {
 		log.Printf("Error getting movies for API: %v", err)
-		http.Error(w, `{"error": "Failed to fetch movies"}`, http.StatusInternalServerError)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch movies")
 		return
 	}
+	if movies == nil {
+		movies = []models.Movie{}
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
+	facets, err := h.movieRepo.MovieFacets(r.Context(), filter)
+	if err != nil {
+		log.Printf("Error counting movie facets for API: %v", err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch movies")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
 		"movies": movies,
+		"facets": facets,
 		"pagination": map[string]interface{}{
-			"page":  page,
-			"limit": limit,
-			"total": len(movies), // Note: You might want to add a count method
+			"page":        page,
+			"limit":       filter.Limit,
+			"total":       total,
+			"total_pages": totalPages(total, filter.Limit),
 		},
 	})
 }
@@ -144,16 +221,32 @@ func (h *Handler) HandleAPIMovieDetail(w http.ResponseWriter, r *http.Request) {
 	movieIDStr := chi.URLParam(r, "id")
 	movieID, err := uuid.Parse(movieIDStr)
 	if err != nil {
-		http.Error(w, `{"error": "Invalid movie ID"}`, http.StatusBadRequest)
+		response.Error(w, r, http.StatusBadRequest, response.CodeBadRequest, "Invalid movie ID")
 		return
 	}
 
 	movie, err := h.movieRepo.GetMovieByID(r.Context(), movieID)
 	if err != nil {
-		http.Error(w, `{"error": "Movie not found"}`, http.StatusNotFound)
+		response.Error(w, r, http.StatusNotFound, response.CodeNotFound, "Movie not found")
+		return
+	}
+
+	credits, err := h.personRepo.GetMovieCredits(r.Context(), movieID)
+	if err != nil {
+		log.Printf("Error getting credits of movie %s for API: %v", movieID, err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch movie credits")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(movie)
+	// Filmo laukai ir cast/crew viename objekte
+	response.JSON(w, http.StatusOK, struct {
+		*models.Movie
+		models.MovieCredits
+	}{movie, credits})
+}
+
+// HandleAPIDocs - /api/v1 dokumentacijos puslapis
+func (h *Handler) HandleAPIDocs(w http.ResponseWriter, r *http.Request) {
+	component := templates.APIDocsPage()
+	component.Render(r.Context(), w)
 }
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"battleNet/internal/response"
+	"battleNet/internal/validation"
+	"battleNet/models"
+	"battleNet/repository"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// movieRequest - filmo laukai API užklausoje. nil - laukas nepateiktas
+// (PATCH jo nekeičia, POST/PUT palieka tuščią).
+type movieRequest struct {
+	ImdbID       *string   `json:"imdb_id"`
+	Title        *string   `json:"title"`
+	Overview     *string   `json:"overview"`
+	ReleaseDate  *string   `json:"release_date"` // YYYY-MM-DD
+	PosterPath   *string   `json:"poster_path"`
+	BackdropPath *string   `json:"backdrop_path"`
+	VoteAverage  *float64  `json:"vote_average"`
+	VoteCount    *int      `json:"vote_count"`
+	Popularity   *float64  `json:"popularity"`
+	Runtime      *int      `json:"runtime"`
+	Status       *string   `json:"status"`
+	Genres       *[]string `json:"genres"` // žanrų pavadinimai
+
+	genres []models.Genre // rasti žanrai (resolveGenres)
+}
+
+// movieRequestFromForm nuskaito HTML formos laukus. Skaičių ir datų
+// formato klaidos įrašomos į v; tušti skaitiniai laukai paliekami nil.
+func movieRequestFromForm(form url.Values, v *validation.Validator) *movieRequest {
+	req := &movieRequest{
+		Title:       stringPtr(form.Get("title")),
+		Overview:    stringPtr(form.Get("overview")),
+		ReleaseDate: stringPtr(form.Get("release_date")),
+		VoteAverage: v.Float("vote_average", form.Get("vote_average")),
+		VoteCount:   v.Int("vote_count", form.Get("vote_count")),
+		Popularity:  v.Float("popularity", form.Get("popularity")),
+		Runtime:     v.Int("runtime", form.Get("runtime")),
+	}
+	if imdbID := strings.TrimSpace(form.Get("imdb_id")); imdbID != "" {
+		req.ImdbID = &imdbID
+	}
+	if status := form.Get("status"); status != "" {
+		req.Status = &status
+	}
+	// Forma visada siunčia visą žanrų sąrašą - nieko nepažymėjus žanrai išvalomi
+	genres := form["genres"]
+	req.Genres = &genres
+	return req
+}
+
+// validate tikrina laukus. partial - PATCH, kai title neprivalomas.
+func (req *movieRequest) validate(v *validation.Validator, partial bool) {
+	if req.Title != nil || !partial {
+		var title string
+		if req.Title != nil {
+			title = *req.Title
+		}
+		v.Required("title", title)
+		v.MaxLength("title", strings.TrimSpace(title), 500)
+	}
+	if req.ImdbID != nil {
+		v.MaxLength("imdb_id", *req.ImdbID, 20)
+	}
+	if req.ReleaseDate != nil {
+		v.Date("release_date", *req.ReleaseDate)
+	}
+	if req.VoteAverage != nil {
+		v.Between("vote_average", *req.VoteAverage, 0, 10)
+	}
+	if req.VoteCount != nil {
+		v.NotNegative("vote_count", float64(*req.VoteCount))
+	}
+	if req.Popularity != nil {
+		v.NotNegative("popularity", *req.Popularity)
+		v.Check(*req.Popularity < 1e6, "popularity", "must be less than 1000000")
+	}
+	if req.Runtime != nil {
+		v.NotNegative("runtime", float64(*req.Runtime))
+	}
+	if req.Status != nil {
+		v.OneOf("status", *req.Status, models.MovieStatuses)
+	}
+}
+
+// resolveGenres patikrina req.Genres pavadinimus pagal genre lentelę (be
+// didžiųjų raidžių skirtumo) ir įsimena rastus žanrus, kuriuos perkelia apply
+func (h *Handler) resolveGenres(ctx context.Context, v *validation.Validator, req *movieRequest) error {
+	if req.Genres == nil {
+		return nil
+	}
+
+	known, err := h.movieRepo.GetGenres(ctx)
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]models.Genre, len(known))
+	for _, genre := range known {
+		byName[strings.ToLower(genre.Name)] = genre
+	}
+
+	req.genres = []models.Genre{}
+	seen := make(map[uuid.UUID]bool)
+	for _, name := range *req.Genres {
+		genre, ok := byName[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			v.AddError("genres", "unknown genre: "+name)
+			continue
+		}
+		if !seen[genre.GenreID] {
+			seen[genre.GenreID] = true
+			req.genres = append(req.genres, genre)
+		}
+	}
+	return nil
+}
+
+// apply perkelia pateiktus laukus į movie (turi būti validuota)
+func (req *movieRequest) apply(movie *models.Movie) {
+	if req.ImdbID != nil {
+		movie.ImdbID = req.ImdbID
+	}
+	if req.Title != nil {
+		movie.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Overview != nil {
+		movie.Overview = req.Overview
+	}
+	if req.ReleaseDate != nil {
+		movie.ReleaseDate = nil
+		if *req.ReleaseDate != "" {
+			releaseDate, _ := time.Parse(validation.DateLayout, *req.ReleaseDate)
+			movie.ReleaseDate = &releaseDate
+		}
+	}
+	if req.PosterPath != nil {
+		movie.PosterPath = req.PosterPath
+	}
+	if req.BackdropPath != nil {
+		movie.BackdropPath = req.BackdropPath
+	}
+	if req.VoteAverage != nil {
+		movie.VoteAverage = req.VoteAverage
+	}
+	if req.VoteCount != nil {
+		movie.VoteCount = req.VoteCount
+	}
+	if req.Popularity != nil {
+		movie.Popularity = req.Popularity
+	}
+	if req.Runtime != nil {
+		movie.Runtime = req.Runtime
+	}
+	if req.Status != nil {
+		movie.Status = req.Status
+	}
+	if req.Genres != nil {
+		movie.Genres = req.genres
+	}
+	if movie.Genres == nil {
+		movie.Genres = []models.Genre{}
+	}
+}
+
+// decodeMovieRequest nuskaito ir validuoja užklausą. Klaidos atveju atsakymas jau parašytas.
+func (h *Handler) decodeMovieRequest(w http.ResponseWriter, r *http.Request, partial bool) (*movieRequest, bool) {
+	var req movieRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		response.Error(w, r, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request body")
+		return nil, false
+	}
+
+	v := validation.New()
+	req.validate(v, partial)
+	if err := h.resolveGenres(r.Context(), v, &req); err != nil {
+		log.Printf("Error loading genres: %v", err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to load genres")
+		return nil, false
+	}
+	if !v.Valid() {
+		response.ValidationError(w, r, v.Errors)
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// newAPIMovie - filmas, kurį užpildo POST ir PUT; be status laikomas "Released"
+func newAPIMovie() *models.Movie {
+	released := "Released"
+	return &models.Movie{Status: &released}
+}
+
+// HandleAPICreateMovie - POST /api/v1/movies
+func (h *Handler) HandleAPICreateMovie(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeMovieRequest(w, r, false)
+	if !ok {
+		return
+	}
+
+	movie := newAPIMovie()
+	req.apply(movie)
+
+	if err := h.movieRepo.CreateMovie(r.Context(), movie); err != nil {
+		if errors.Is(err, repository.ErrDuplicateImdbID) {
+			response.Error(w, r, http.StatusConflict, response.CodeConflict, "A movie with this imdb_id already exists")
+			return
+		}
+		log.Printf("Error creating movie via API: %v", err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to create movie")
+		return
+	}
+	h.recordAudit(r, auditMovieCreate, "movie", movie.MovieID.String(), nil, movie)
+
+	response.JSON(w, http.StatusCreated, movie)
+}
+
+// HandleAPIUpdateMovie - PUT /api/v1/movies/{id} (visi laukai pakeičiami)
+func (h *Handler) HandleAPIUpdateMovie(w http.ResponseWriter, r *http.Request) {
+	h.updateMovieAPI(w, r, false)
+}
+
+// HandleAPIPatchMovie - PATCH /api/v1/movies/{id} (keičiami tik pateikti laukai)
+func (h *Handler) HandleAPIPatchMovie(w http.ResponseWriter, r *http.Request) {
+	h.updateMovieAPI(w, r, true)
+}
+
+func (h *Handler) updateMovieAPI(w http.ResponseWriter, r *http.Request, partial bool) {
+	movieID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, response.CodeBadRequest, "Invalid movie ID")
+		return
+	}
+
+	req, ok := h.decodeMovieRequest(w, r, partial)
+	if !ok {
+		return
+	}
+
+	before, err := h.movieRepo.GetMovieByID(r.Context(), movieID)
+	if err != nil {
+		response.Error(w, r, http.StatusNotFound, response.CodeNotFound, "Movie not found")
+		return
+	}
+
+	movie := newAPIMovie()
+	if partial {
+		*movie = *before
+	}
+	req.apply(movie)
+
+	if err := h.movieRepo.ReplaceMovie(r.Context(), movieID, movie); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrDuplicateImdbID):
+			response.Error(w, r, http.StatusConflict, response.CodeConflict, "A movie with this imdb_id already exists")
+		case errors.Is(err, pgx.ErrNoRows):
+			response.Error(w, r, http.StatusNotFound, response.CodeNotFound, "Movie not found")
+		default:
+			log.Printf("Error updating movie %s via API: %v", movieID, err)
+			response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to update movie")
+		}
+		return
+	}
+	h.recordAudit(r, auditMovieUpdate, "movie", movieID.String(), before, movie)
+
+	response.JSON(w, http.StatusOK, movie)
+}
+
+// HandleAPIDeleteMovie - DELETE /api/v1/movies/{id}
+func (h *Handler) HandleAPIDeleteMovie(w http.ResponseWriter, r *http.Request) {
+	movieID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, response.CodeBadRequest, "Invalid movie ID")
+		return
+	}
+
+	before, err := h.movieRepo.GetMovieByID(r.Context(), movieID)
+	if err != nil {
+		response.Error(w, r, http.StatusNotFound, response.CodeNotFound, "Movie not found")
+		return
+	}
+
+	if err := h.movieRepo.DeleteMovie(r.Context(), movieID); err != nil {
+		log.Printf("Error deleting movie %s via API: %v", movieID, err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to delete movie")
+		return
+	}
+	h.recordAudit(r, auditMovieDelete, "movie", movieID.String(), before, nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"battleNet/internal/validation"
+	"battleNet/models"
+)
+
+const (
+	moviesPerPage    = 20
+	maxMoviesPerPage = 100
+	// maxSearchLength - ilgesnė paieškos užklausa nukerpama
+	maxSearchLength = 200
+)
+
+// parseMovieFilter nuskaito filmų sąrašo query:
+//
+//	q                       paieška
+//	genre, status           galima kartoti - tinka filmai, turintys bent vieną iš reikšmių
+//	year_from, year_to      išleidimo metai imtinai
+//	runtime_min, runtime_max  trukmė minutėmis imtinai
+//	min_vote                TMDB reitingas 0-10
+//	min_rating              mūsų recenzijų vidurkis 1-10
+//	sort, order             models.MovieSorts; asc|desc (numatyta: title - asc, kiti - desc)
+//	page, limit
+//
+// Netinkami page/limit pakeičiami numatytaisiais, kaip ir anksčiau; kitų
+// netinkamos reikšmės grąžinamos kaip klaidos pagal lauką (nil, jei klaidų nėra).
+func parseMovieFilter(query url.Values) (models.MovieFilter, int, validation.Errors) {
+	page, _ := strconv.Atoi(query.Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if limit < 1 || limit > maxMoviesPerPage {
+		limit = moviesPerPage
+	}
+
+	filter := models.MovieFilter{Limit: limit, Offset: (page - 1) * limit}
+	if q := []rune(strings.TrimSpace(query.Get("q"))); len(q) > maxSearchLength {
+		filter.Query = strings.TrimSpace(string(q[:maxSearchLength]))
+	} else {
+		filter.Query = string(q)
+	}
+	filter.Genres = nonEmpty(query["genre"])
+	filter.Statuses = nonEmpty(query["status"])
+
+	v := validation.New()
+	for _, status := range filter.Statuses {
+		v.OneOf("status", status, models.MovieStatuses)
+	}
+
+	// Int grąžina nil tuščiai ar neteisingai reikšmei, tada intervalas netikrinamas
+	intParam := func(field string, min, max float64) *int {
+		n := v.Int(field, query.Get(field))
+		if n != nil {
+			v.Between(field, float64(*n), min, max)
+		}
+		return n
+	}
+	filter.YearFrom = intParam("year_from", 1800, 2200)
+	filter.YearTo = intParam("year_to", 1800, 2200)
+	filter.RuntimeMin = intParam("runtime_min", 0, 1000)
+	filter.RuntimeMax = intParam("runtime_max", 0, 1000)
+	if filter.YearFrom != nil && filter.YearTo != nil {
+		v.Check(*filter.YearFrom <= *filter.YearTo, "year_to", "must not be before year_from")
+	}
+	if filter.RuntimeMin != nil && filter.RuntimeMax != nil {
+		v.Check(*filter.RuntimeMin <= *filter.RuntimeMax, "runtime_max", "must not be less than runtime_min")
+	}
+
+	if filter.MinVoteAverage = v.Float("min_vote", query.Get("min_vote")); filter.MinVoteAverage != nil {
+		v.Between("min_vote", *filter.MinVoteAverage, 0, 10)
+	}
+	if filter.MinRating = v.Float("min_rating", query.Get("min_rating")); filter.MinRating != nil {
+		v.Between("min_rating", *filter.MinRating, 1, 10)
+	}
+
+	if filter.Sort = query.Get("sort"); filter.Sort != "" {
+		v.OneOf("sort", filter.Sort, models.MovieSorts)
+	}
+	switch order := query.Get("order"); order {
+	case "asc":
+	case "desc":
+		filter.Descending = true
+	case "":
+		// Abėcėlė natūraliai didėjančiai, skaičiai ir datos - didžiausi pirmi
+		filter.Descending = filter.Sort != "title"
+	default:
+		v.AddError("order", "must be one of: asc, desc")
+	}
+
+	if !v.Valid() {
+		return filter, page, v.Errors
+	}
+	return filter, page, nil
+}
+
+// nonEmpty - reikšmės be tarpų kraštuose, tuščios ir pasikartojančios praleidžiamos
+func nonEmpty(values []string) []string {
+	var result []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" && !slices.Contains(result, value) {
+			result = append(result, value)
+		}
+	}
+	return result
+}
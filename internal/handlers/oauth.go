@@ -0,0 +1,409 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"battleNet/auth/oauth"
+	"battleNet/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var usernameCleaner = regexp.MustCompile(`[^a-z0-9_]+`)
+
+var errIdentityLinkedElsewhere = errors.New("identity is linked to another account")
+
+// errUnverifiedLocalAccount - paskyra su tuo pačiu email yra, bet jos email
+// nepatvirtintas, todėl negalima įrodyti, kad ją sukūrė tas pats žmogus
+var errUnverifiedLocalAccount = errors.New("local account with this email is not verified")
+
+// HandleOAuthLogin - nukreipia vartotoją į tiekėjo prisijungimą (authorization code + PKCE)
+func (h *Handler) HandleOAuthLogin(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.oauthProviders.Get(chi.URLParam(r, "provider"))
+	if !ok {
+		h.renderLogin(w, r, "This sign-in provider is not configured")
+		return
+	}
+
+	h.startOAuthFlow(w, r, provider, false)
+}
+
+// HandleOAuthLink - pradeda naujos tapatybės susiejimą su prisijungusiu vartotoju
+func (h *Handler) HandleOAuthLink(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.oauthProviders.Get(chi.URLParam(r, "provider"))
+	if !ok {
+		http.Error(w, "Unknown provider", http.StatusNotFound)
+		return
+	}
+
+	h.startOAuthFlow(w, r, provider, true)
+}
+
+func (h *Handler) startOAuthFlow(w http.ResponseWriter, r *http.Request, provider oauth.Provider, link bool) {
+	state, err := randomToken(32)
+	if err != nil {
+		log.Printf("Failed to generate OAuth state: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	verifier, err := randomToken(32)
+	if err != nil {
+		log.Printf("Failed to generate PKCE verifier: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.sessionManager.Put(r.Context(), "oauth_state", state)
+	h.sessionManager.Put(r.Context(), "oauth_verifier", verifier)
+	h.sessionManager.Put(r.Context(), "oauth_provider", provider.Name())
+	h.sessionManager.Put(r.Context(), "oauth_link", link)
+
+	http.Redirect(w, r, provider.AuthCodeURL(state, pkceChallenge(verifier)), http.StatusSeeOther)
+}
+
+// HandleOAuthCallback - apdoroja tiekėjo atsakymą: prijungia arba susieja vartotoją
+func (h *Handler) HandleOAuthCallback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	expectedState := h.sessionManager.PopString(r.Context(), "oauth_state")
+	verifier := h.sessionManager.PopString(r.Context(), "oauth_verifier")
+	expectedProvider := h.sessionManager.PopString(r.Context(), "oauth_provider")
+	link := h.sessionManager.PopBool(r.Context(), "oauth_link")
+
+	provider, ok := h.oauthProviders.Get(chi.URLParam(r, "provider"))
+	if !ok {
+		h.renderLogin(w, r, "This sign-in provider is not configured")
+		return
+	}
+
+	if providerErr := query.Get("error"); providerErr != "" {
+		log.Printf("%s OAuth returned error: %s", provider.Name(), providerErr)
+		h.finishOAuthWithError(w, r, link, provider.DisplayName()+" sign-in was cancelled")
+		return
+	}
+
+	state := query.Get("state")
+	if expectedState == "" || verifier == "" || expectedProvider != provider.Name() ||
+		subtle.ConstantTimeCompare([]byte(state), []byte(expectedState)) != 1 {
+		log.Printf("%s OAuth state mismatch", provider.Name())
+		h.finishOAuthWithError(w, r, link, "Sign-in session expired, please try again")
+		return
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		http.Error(w, "Missing authorization code", http.StatusBadRequest)
+		return
+	}
+
+	token, err := provider.Exchange(r.Context(), code, verifier)
+	if err != nil {
+		log.Printf("%s token exchange failed: %v", provider.Name(), err)
+		h.finishOAuthWithError(w, r, link, provider.DisplayName()+" sign-in failed")
+		return
+	}
+
+	identity, err := provider.FetchIdentity(r.Context(), token)
+	if err != nil {
+		log.Printf("%s identity request failed: %v", provider.Name(), err)
+		h.finishOAuthWithError(w, r, link, provider.DisplayName()+" sign-in failed")
+		return
+	}
+
+	if link {
+		h.completeOAuthLink(w, r, provider, identity, token)
+		return
+	}
+
+	user, err := h.findOrCreateOAuthUser(r.Context(), provider.Name(), identity, token)
+	if err != nil {
+		suspended, lifted := h.oauthSuspension(r.Context(), provider.Name(), identity)
+		if suspended != nil {
+			h.recordLoginAttempt(r.Context(), r, identity.Email, &suspended.User.UserID, false, "suspended")
+			h.renderLogin(w, r, suspensionMessage(suspended.Suspension))
+			return
+		}
+		if lifted {
+			user, err = h.findOrCreateOAuthUser(r.Context(), provider.Name(), identity, token)
+		}
+	}
+	if errors.Is(err, errUnverifiedLocalAccount) {
+		h.renderLogin(w, r, "An account with this email already exists. Sign in with your password and connect "+
+			provider.DisplayName()+" from your profile.")
+		return
+	}
+	if err != nil {
+		log.Printf("%s sign-in failed for %s: %v", provider.Name(), identity.Email, err)
+		h.renderLogin(w, r, "Could not sign in with this "+provider.DisplayName()+" account")
+		return
+	}
+
+	h.completeLogin(w, r, user, provider.Name())
+}
+
+// completeOAuthLink susieja tapatybę su šiuo metu prisijungusiu vartotoju
+func (h *Handler) completeOAuthLink(w http.ResponseWriter, r *http.Request, provider oauth.Provider, identity *oauth.Identity, token *models.TokenResponse) {
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil || !h.sessionManager.GetBool(r.Context(), "authenticated") {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	existing, err := h.oauthRepo.GetOAuthByProvider(r.Context(), provider.Name(), identity.ProviderID)
+	switch {
+	case err == nil && existing.UserID == userID:
+		h.sessionManager.Put(r.Context(), "flash_success", provider.DisplayName()+" account is already linked")
+	case err == nil:
+		h.sessionManager.Put(r.Context(), "flash_error", "This "+provider.DisplayName()+" account is linked to another user")
+	case errors.Is(err, pgx.ErrNoRows):
+		link := &models.OAuth{
+			UserID:        userID,
+			Provider:      provider.Name(),
+			ProviderID:    identity.ProviderID,
+			ProviderEmail: identity.Email,
+			AccessToken:   token.AccessToken,
+			RefreshToken:  token.RefreshToken,
+		}
+		if err := h.oauthRepo.CreateOAuth(r.Context(), link); err != nil {
+			log.Printf("Error linking %s account: %v", provider.Name(), err)
+			h.sessionManager.Put(r.Context(), "flash_error", "Failed to link "+provider.DisplayName()+" account")
+		} else {
+			log.Printf("User %s linked %s account", userID, provider.Name())
+			h.sessionManager.Put(r.Context(), "flash_success", provider.DisplayName()+" account linked")
+		}
+	default:
+		log.Printf("Error checking %s identity: %v", provider.Name(), err)
+		h.sessionManager.Put(r.Context(), "flash_error", "Failed to link "+provider.DisplayName()+" account")
+	}
+
+	http.Redirect(w, r, "/profile", http.StatusSeeOther)
+}
+
+// HandleOAuthUnlink - atsieja tapatybę, jei vartotojui lieka kitas prisijungimo būdas
+func (h *Handler) HandleOAuthUnlink(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil {
+		http.Error(w, "Invalid session", http.StatusBadRequest)
+		return
+	}
+
+	oauthProviderID, err := uuid.Parse(r.FormValue("oauth_provider_id"))
+	if err != nil {
+		http.Error(w, "Invalid identity ID", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	identities, err := h.oauthRepo.GetUserOAuths(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error getting linked identities: %v", err)
+		http.Error(w, "Failed to load linked accounts", http.StatusInternalServerError)
+		return
+	}
+
+	// Be slaptažodžio paskutinė susieta tapatybė yra vienintelis būdas prisijungti
+	if user.PasswordHash == "" && len(identities) <= 1 {
+		h.sessionManager.Put(r.Context(), "flash_error", "You cannot unlink your only sign-in method. Set a password or link another account first.")
+		http.Redirect(w, r, "/profile", http.StatusSeeOther)
+		return
+	}
+
+	if err := h.oauthRepo.DeleteOAuth(r.Context(), userID, oauthProviderID); err != nil {
+		log.Printf("Error unlinking identity: %v", err)
+		h.sessionManager.Put(r.Context(), "flash_error", "Failed to unlink account")
+		http.Redirect(w, r, "/profile", http.StatusSeeOther)
+		return
+	}
+
+	h.sessionManager.Put(r.Context(), "flash_success", "Account unlinked")
+	http.Redirect(w, r, "/profile", http.StatusSeeOther)
+}
+
+func (h *Handler) finishOAuthWithError(w http.ResponseWriter, r *http.Request, link bool, message string) {
+	if link {
+		h.sessionManager.Put(r.Context(), "flash_error", message)
+		http.Redirect(w, r, "/profile", http.StatusSeeOther)
+		return
+	}
+	h.renderLogin(w, r, message)
+}
+
+// oauthProviderList - sukonfigūruoti tiekėjai šablonams
+func (h *Handler) oauthProviderList() []models.OAuthProvider {
+	var providers []models.OAuthProvider
+	for _, p := range h.oauthProviders.Providers() {
+		providers = append(providers, models.OAuthProvider{Name: p.Name(), DisplayName: p.DisplayName()})
+	}
+	return providers
+}
+
+// oauthSuspension grąžina tapatybės vartotojo aktyvią suspensiją. Jei ji jau
+// pasibaigė, nuima ją ir grąžina lifted = true.
+func (h *Handler) oauthSuspension(ctx context.Context, provider string, identity *oauth.Identity) (suspended *models.SuspendedUser, lifted bool) {
+	var err error
+	if existing, linkErr := h.oauthRepo.GetOAuthByProvider(ctx, provider, identity.ProviderID); linkErr == nil {
+		suspended, err = h.suspensionRepo.GetSuspendedUser(ctx, existing.UserID)
+	} else if identity.Email != "" && identity.EmailVerified {
+		suspended, err = h.suspensionRepo.GetSuspendedUserByEmail(ctx, identity.Email)
+	} else {
+		return nil, false
+	}
+	if err != nil {
+		return nil, false
+	}
+
+	if suspended.Suspension.Expired() {
+		if err := h.suspensionRepo.Lift(ctx, suspended.User.UserID, nil); err != nil {
+			log.Printf("Failed to lift expired suspension of %s: %v", suspended.User.Email, err)
+			return nil, false
+		}
+		return nil, true
+	}
+	return suspended, false
+}
+
+// findOrCreateOAuthUser finds the user linked to the provider identity,
+// links an existing account with the same verified email, or creates a new one.
+// Existing account is linked only if its own email is verified too - otherwise
+// anyone could pre-register the victim's email with a password and keep access
+// after the victim signs in with the provider (pre-account takeover).
+func (h *Handler) findOrCreateOAuthUser(ctx context.Context, provider string, identity *oauth.Identity, token *models.TokenResponse) (*models.User, error) {
+	existing, err := h.oauthRepo.GetOAuthByProvider(ctx, provider, identity.ProviderID)
+	if err == nil {
+		if err := h.oauthRepo.UpdateOAuthTokens(ctx, existing.OAuthProviderID, token.AccessToken, token.RefreshToken); err != nil {
+			log.Printf("Failed to update OAuth tokens for user %s: %v", existing.UserID, err)
+		}
+		return h.userRepo.GetUserByID(ctx, existing.UserID)
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	// Nepatvirtintas email negali būti naudojamas paskyrų susiejimui
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, errors.New("provider email is missing or not verified")
+	}
+
+	user, err := h.userRepo.GetUserByEmail(ctx, identity.Email)
+	if err == nil && !user.EmailVerified {
+		return nil, errUnverifiedLocalAccount
+	}
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+
+		username, err := h.generateUsername(ctx, identity.Email)
+		if err != nil {
+			return nil, err
+		}
+
+		user = &models.User{
+			Email:         identity.Email,
+			PasswordHash:  "", // Prisijungimas tik per OAuth
+			FirstName:     identity.FirstName,
+			LastName:      identity.LastName,
+			Username:      username,
+			Role:          "user",
+			IsActive:      true,
+			EmailVerified: true,
+		}
+		if identity.AvatarURL != "" {
+			user.AvatarURL = stringPtr(identity.AvatarURL)
+		}
+
+		if err := h.userRepo.CreateUser(ctx, user); err != nil {
+			return nil, err
+		}
+		log.Printf("New user registered with %s: %s (id: %s)", provider, user.Email, user.UserID)
+	}
+
+	link := &models.OAuth{
+		UserID:        user.UserID,
+		Provider:      provider,
+		ProviderID:    identity.ProviderID,
+		ProviderEmail: identity.Email,
+		AccessToken:   token.AccessToken,
+		RefreshToken:  token.RefreshToken,
+	}
+	if err := h.oauthRepo.CreateOAuth(ctx, link); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// generateUsername sukuria unikalų vartotojo vardą iš email
+func (h *Handler) generateUsername(ctx context.Context, email string) (string, error) {
+	base := strings.ToLower(strings.SplitN(email, "@", 2)[0])
+	base = strings.Trim(usernameCleaner.ReplaceAllString(base, "_"), "_")
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > 30 {
+		base = base[:30]
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		exists, err := h.userRepo.UsernameExists(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return candidate, nil
+		}
+
+		suffix := make([]byte, 3)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", err
+		}
+		candidate = base + "_" + hex.EncodeToString(suffix)
+	}
+
+	return "", errors.New("could not generate a unique username")
+}
+
+// randomToken grąžina atsitiktinį base64url string'ą
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken - DB saugomas tik vienkartinių token'ų SHA-256 hash
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// pkceChallenge - S256 code challenge pagal RFC 7636
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
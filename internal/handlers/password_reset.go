@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"battleNet/internal/validation"
+	"battleNet/mail"
+	"battleNet/models"
+	"battleNet/repository"
+	"battleNet/templates"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	passwordResetTTL         = time.Hour
+	passwordResetSendTimeout = 30 * time.Second
+	forgotPasswordMessage    = "If an account with that email exists, we've sent a link to reset your password."
+
+	// Atkūrimo laiškų riba per email ir per IP; viršijus laiškas nesiunčiamas
+	passwordResetEmailLimit  = 3
+	passwordResetIPLimit     = 10
+	passwordResetLimitWindow = time.Hour
+)
+
+// HandleForgotPasswordPage - rodo slaptažodžio atkūrimo formą
+func (h *Handler) HandleForgotPasswordPage(w http.ResponseWriter, r *http.Request) {
+	component := templates.ForgotPasswordPage("")
+	component.Render(r.Context(), w)
+}
+
+// HandleForgotPassword - išsiunčia atkūrimo nuorodą. Atsakymas visada vienodas,
+// kad nebūtų galima sužinoti ar email'as užregistruotas.
+func (h *Handler) HandleForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	email := strings.TrimSpace(r.FormValue("email"))
+	if !h.passwordResetAllowed(r.Context(), email, clientIP(r)) {
+		log.Printf("Password reset rate limit reached for %s from %s", email, clientIP(r))
+		component := templates.ForgotPasswordPage(forgotPasswordMessage)
+		component.Render(r.Context(), w)
+		return
+	}
+
+	user, err := h.userRepo.GetUserByEmail(r.Context(), email)
+	switch {
+	case err == nil:
+		// Siunčiame fone, kad atsakymo laikas neišduotų ar vartotojas egzistuoja
+		go func(user *models.User) {
+			ctx, cancel := context.WithTimeout(context.Background(), passwordResetSendTimeout)
+			defer cancel()
+
+			if err := h.sendPasswordResetEmail(ctx, user); err != nil {
+				log.Printf("Failed to send password reset email to %s: %v", user.Email, err)
+			}
+		}(user)
+	case errors.Is(err, pgx.ErrNoRows):
+		log.Printf("Password reset requested for unknown email %s", email)
+	default:
+		log.Printf("Error looking up user for password reset: %v", err)
+	}
+
+	component := templates.ForgotPasswordPage(forgotPasswordMessage)
+	component.Render(r.Context(), w)
+}
+
+// passwordResetAllowed suskaičiuoja atkūrimo užklausą per email ir per IP
+// (tais pačiais login_lockout skaitliukais kaip prisijungimo ribojimas) ir
+// grąžina false, jei kuri nors riba viršyta. DB klaidos atveju neriboja.
+func (h *Handler) passwordResetAllowed(ctx context.Context, email, ip string) bool {
+	allowed := true
+	for _, limit := range []struct {
+		scope, subject string
+		max            int
+	}{
+		{repository.LockoutScopeResetEmail, normalizeEmail(email), passwordResetEmailLimit},
+		{repository.LockoutScopeResetIP, ip, passwordResetIPLimit},
+	} {
+		if limit.subject == "" {
+			continue
+		}
+		requests, err := h.loginAttemptRepo.RegisterFailure(ctx, limit.scope, limit.subject, passwordResetLimitWindow)
+		if err != nil {
+			log.Printf("Failed to count password reset request for %s %s: %v", limit.scope, limit.subject, err)
+			continue
+		}
+		if requests > limit.max {
+			allowed = false
+		}
+	}
+	return allowed
+}
+
+// HandleResetPasswordPage - rodo naujo slaptažodžio formą
+func (h *Handler) HandleResetPasswordPage(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+
+	valid, err := h.passwordResetRepo.IsTokenValid(r.Context(), hashToken(token))
+	if err != nil {
+		log.Printf("Error checking password reset token: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if token == "" || !valid {
+		component := templates.ResetPasswordInvalidPage()
+		component.Render(r.Context(), w)
+		return
+	}
+
+	component := templates.ResetPasswordPage(token, "")
+	component.Render(r.Context(), w)
+}
+
+// HandleResetPassword - nustato naują slaptažodį ir atjungia visas vartotojo sesijas
+func (h *Handler) HandleResetPassword(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	token := r.FormValue("token")
+	newPassword := r.FormValue("new_password")
+	confirmPassword := r.FormValue("confirm_password")
+
+	if newPassword != confirmPassword {
+		component := templates.ResetPasswordPage(token, "Passwords do not match")
+		component.Render(r.Context(), w)
+		return
+	}
+
+	v := validation.New()
+	v.Password("new_password", newPassword)
+	if !v.Valid() {
+		component := templates.ResetPasswordPage(token, "Password "+v.Errors.Get("new_password"))
+		component.Render(r.Context(), w)
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("Failed to hash password: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	userID, err := h.passwordResetRepo.ResetPassword(r.Context(), hashToken(token), string(hashedPassword))
+	if errors.Is(err, pgx.ErrNoRows) {
+		component := templates.ResetPasswordInvalidPage()
+		component.Render(r.Context(), w)
+		return
+	}
+	if err != nil {
+		log.Printf("Error resetting password: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := h.sessionStore.DeleteByUser(r.Context(), userID, ""); err != nil {
+		log.Printf("Failed to destroy sessions for user %s: %v", userID, err)
+	}
+	if err := h.apiSessionRepo.RevokeAllForUser(r.Context(), userID); err != nil {
+		log.Printf("Failed to revoke API sessions for user %s: %v", userID, err)
+	}
+
+	// Dabartinė naršyklės sesija irgi nebegalioja
+	if err := h.sessionManager.Destroy(r.Context()); err != nil {
+		log.Printf("Failed to destroy current session: %v", err)
+	}
+
+	log.Printf("Password reset for user %s", userID)
+	component := templates.LoginPageWithSuccess("Your password has been reset. You can now log in with your new password.", h.oauthProviderList())
+	component.Render(r.Context(), w)
+}
+
+// sendPasswordResetEmail sukuria naują vienkartinį token'ą ir išsiunčia nuorodą
+func (h *Handler) sendPasswordResetEmail(ctx context.Context, user *models.User) error {
+	token, err := randomToken(32)
+	if err != nil {
+		return err
+	}
+
+	if err := h.passwordResetRepo.CreateToken(ctx, user.UserID, hashToken(token), time.Now().Add(passwordResetTTL)); err != nil {
+		return err
+	}
+
+	link := h.appBaseURL + "/reset-password?token=" + url.QueryEscape(token)
+
+	return h.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your BattleNet password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThe link expires in %d minutes and can only be used once. If you did not request a password reset, you can ignore this email.\n",
+			user.FirstName, link, int(passwordResetTTL.Minutes()),
+		),
+	})
+}
@@ -0,0 +1,54 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// Po passwordResetEmailLimit (3) užklausų tam pačiam email'ui laiškai nebesiunčiami,
+// bet atsakymas lieka toks pat.
+func TestForgotPasswordRateLimit(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser(t, "user")
+
+	clearLimits := func() {
+		_, err := app.db.Pool.Exec(context.Background(),
+			`DELETE FROM login_lockout WHERE (scope = 'reset_email' AND subject = $1) OR scope = 'reset_ip'`, user.Email)
+		if err != nil {
+			t.Errorf("clear reset limits: %v", err)
+		}
+	}
+	clearLimits()
+	t.Cleanup(clearLimits)
+
+	c := app.client(t)
+	for i := 0; i < 5; i++ {
+		resp := c.postForm("/forgot-password", "/forgot-password", url.Values{"email": {user.Email}})
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d: status %d", i+1, resp.StatusCode)
+		}
+	}
+
+	// Laiškai siunčiami fone - palaukiam, kol sukurti visi leisti token'ai
+	tokens := func() int {
+		var n int
+		err := app.db.Pool.QueryRow(context.Background(),
+			`SELECT COUNT(*) FROM password_reset_token WHERE user_id = $1`, user.UserID).Scan(&n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for tokens() < 3 && time.Now().Before(deadline) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	if n := tokens(); n != 3 {
+		t.Errorf("reset tokens created = %d, want 3", n)
+	}
+}
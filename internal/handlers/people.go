@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"battleNet/internal/response"
+	"battleNet/models"
+	"battleNet/templates"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// personDetailsTTL - po kiek laiko asmens informacija iš TMDB atnaujinama
+const personDetailsTTL = 30 * 24 * time.Hour
+
+// loadPerson grąžina asmenį iš DB. Biografija ir kiti duomenys iš TMDB
+// parsiunčiami tik atidarius asmens puslapį (ne importuojant filmą) ir
+// atnaujinami kas personDetailsTTL. Jei TMDB nepasiekiamas, rodoma tai, kas išsaugota.
+func (h *Handler) loadPerson(ctx context.Context, personID uuid.UUID) (*models.PersonDetails, error) {
+	person, err := h.personRepo.GetPerson(ctx, personID)
+	if err != nil {
+		return nil, err
+	}
+
+	fresh := person.DetailsFetchedAt != nil && time.Since(*person.DetailsFetchedAt) < personDetailsTTL
+	if fresh || person.TmdbID == nil {
+		return person, nil
+	}
+
+	tmdbPerson, err := h.tmdbClient.GetPerson(ctx, *person.TmdbID)
+	if err != nil {
+		log.Printf("Error fetching TMDB person %d: %v", *person.TmdbID, err)
+		return person, nil
+	}
+
+	updated := *person
+	if tmdbPerson.Name != "" {
+		updated.Name = tmdbPerson.Name
+	}
+	if tmdbPerson.ProfilePath != "" {
+		updated.ProfilePath = stringPtr(formatProfileURL(tmdbPerson.ProfilePath))
+	}
+	updated.Biography = optionalString(tmdbPerson.Biography)
+	updated.Birthday = parseDate(tmdbPerson.Birthday)
+	updated.Deathday = parseDate(tmdbPerson.Deathday)
+	updated.PlaceOfBirth = optionalString(tmdbPerson.PlaceOfBirth)
+	updated.KnownForDepartment = optionalString(tmdbPerson.KnownForDepartment)
+
+	if err := h.personRepo.UpdatePersonDetails(ctx, &updated); err != nil {
+		log.Printf("Error saving details of person %s: %v", personID, err)
+	}
+	return &updated, nil
+}
+
+// optionalString - tuščia eilutė tampa nil (NULL DB)
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// HandlePersonPage - aktoriaus ar komandos nario puslapis su filmografija
+func (h *Handler) HandlePersonPage(w http.ResponseWriter, r *http.Request) {
+	email := h.sessionManager.GetString(r.Context(), "email")
+	role := h.sessionManager.GetString(r.Context(), "role")
+
+	personID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid person ID", http.StatusBadRequest)
+		return
+	}
+
+	person, err := h.loadPerson(r.Context(), personID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			http.Error(w, "Person not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("Error getting person %s: %v", personID, err)
+		http.Error(w, "Failed to load person", http.StatusInternalServerError)
+		return
+	}
+
+	filmography, err := h.personRepo.GetFilmography(r.Context(), personID)
+	if err != nil {
+		log.Printf("Error getting filmography of person %s: %v", personID, err)
+		http.Error(w, "Failed to load filmography", http.StatusInternalServerError)
+		return
+	}
+
+	component := templates.PersonPage(email, role, *person, filmography)
+	component.Render(r.Context(), w)
+}
+
+// HandleAPIPerson - GET /api/v1/people/{id}
+func (h *Handler) HandleAPIPerson(w http.ResponseWriter, r *http.Request) {
+	personID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Error(w, r, http.StatusBadRequest, response.CodeBadRequest, "Invalid person ID")
+		return
+	}
+
+	person, err := h.loadPerson(r.Context(), personID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.Error(w, r, http.StatusNotFound, response.CodeNotFound, "Person not found")
+			return
+		}
+		log.Printf("Error getting person %s for API: %v", personID, err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch person")
+		return
+	}
+
+	filmography, err := h.personRepo.GetFilmography(r.Context(), personID)
+	if err != nil {
+		log.Printf("Error getting filmography of person %s for API: %v", personID, err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch filmography")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, struct {
+		*models.PersonDetails
+		Filmography models.Filmography `json:"filmography"`
+	}{person, filmography})
+}
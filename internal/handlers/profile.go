@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"battleNet/internal/validation"
 	//"battleNet/models"
 	"battleNet/templates"
 	"log"
@@ -133,6 +134,14 @@ func (h *Handler) HandleChangePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	v := validation.New()
+	v.Password("new_password", newPassword)
+	if !v.Valid() {
+		component := templates.ChangePasswordPage(email, role, "New password "+v.Errors.Get("new_password"))
+		component.Render(r.Context(), w)
+		return
+	}
+
 	// Gauti vartotojo dabartinį slaptažodį
 	user, err := h.userRepo.GetUserByID(r.Context(), userID)
 	if err != nil {
@@ -165,6 +174,14 @@ func (h *Handler) HandleChangePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Senu slaptažodžiu atidarytos sesijos nebegalioja; dabartinė lieka
+	if _, err := h.sessionStore.DeleteByUser(r.Context(), userID, h.sessionManager.Token(r.Context())); err != nil {
+		log.Printf("Failed to destroy other sessions for user %s: %v", userID, err)
+	}
+	if err := h.apiSessionRepo.RevokeAllForUser(r.Context(), userID); err != nil {
+		log.Printf("Failed to revoke API sessions for user %s: %v", userID, err)
+	}
+
 	// Sėkmės puslapis
 	html := `
 	<!DOCTYPE html>
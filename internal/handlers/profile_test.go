@@ -0,0 +1,41 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// Pakeitus slaptažodį kitos naršyklės sesijos ir API token'ai nebegalioja,
+// o sesija, kurioje slaptažodis pakeistas, lieka prisijungusi.
+func TestChangePasswordRevokesOtherSessions(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser(t, "user")
+
+	current := app.login(t, user)
+	other := app.login(t, user)
+	token := app.apiToken(t, user)
+
+	if status, body := app.apiRequest(t, http.MethodGet, "/api/v1/watchlist", token, nil); status != http.StatusOK {
+		t.Fatalf("watchlist before change: status %d: %s", status, body)
+	}
+
+	resp := current.postForm("/profile/change-password", "/profile/change-password", url.Values{
+		"current_password": {testPassword},
+		"new_password":     {"another-horse-battery"},
+		"confirm_password": {"another-horse-battery"},
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("change password: status %d", resp.StatusCode)
+	}
+
+	if status := current.get("/profile"); status != http.StatusOK {
+		t.Errorf("current session: GET /profile status %d, want %d", status, http.StatusOK)
+	}
+	if status := other.get("/profile"); status != http.StatusSeeOther {
+		t.Errorf("other session: GET /profile status %d, want %d", status, http.StatusSeeOther)
+	}
+	if status, _ := app.apiRequest(t, http.MethodGet, "/api/v1/watchlist", token, nil); status != http.StatusUnauthorized {
+		t.Errorf("API token after change: status %d, want %d", status, http.StatusUnauthorized)
+	}
+}
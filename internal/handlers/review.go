@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
-	"strconv"
 
+	"battleNet/internal/response"
+	"battleNet/internal/validation"
+	"battleNet/middlewaree"
 	"battleNet/models"
 	//"battleNet/templates"
 
@@ -37,18 +39,22 @@ func (h *Handler) HandleCreateReview(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rating, err := strconv.Atoi(r.FormValue("rating"))
-	if err != nil || rating < 1 || rating > 10 {
-		http.Error(w, "Rating must be between 1 and 10", http.StatusBadRequest)
+	v := validation.New()
+	rating := v.Int("rating", r.FormValue("rating"))
+	v.Check(rating != nil, "rating", "is required")
+	title, content := r.FormValue("title"), r.FormValue("content")
+	validateReview(v, rating, title, content)
+	if !v.Valid() {
+		http.Error(w, v.Errors.Error(), http.StatusUnprocessableEntity)
 		return
 	}
 
 	params := models.CreateReviewParams{
 		UserID:           userID,
 		MovieID:          movieID,
-		Rating:           rating,
-		Title:            r.FormValue("title"),
-		Content:          r.FormValue("content"),
+		Rating:           *rating,
+		Title:            title,
+		Content:          content,
 		ContainsSpoilers: r.FormValue("contains_spoilers") == "on",
 		IsPublic:         true,
 	}
@@ -64,6 +70,16 @@ func (h *Handler) HandleCreateReview(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/movies/"+movieID.String(), http.StatusSeeOther)
 }
 
+// validateReview - bendros recenzijos taisyklės formai ir API
+func validateReview(v *validation.Validator, rating *int, title, content string) {
+	if rating != nil {
+		v.Between("rating", float64(*rating), 1, 10)
+	}
+	v.Required("title", title)
+	v.MaxLength("title", title, 255)
+	v.Required("content", content)
+}
+
 // HandleAPIReviews returns reviews as JSON (API endpoint)
 func (h *Handler) HandleAPIReviews(w http.ResponseWriter, r *http.Request) {
 	movieIDStr := r.URL.Query().Get("movie_id")
@@ -74,32 +90,31 @@ func (h *Handler) HandleAPIReviews(w http.ResponseWriter, r *http.Request) {
 	if movieIDStr != "" {
 		movieID, err := uuid.Parse(movieIDStr)
 		if err != nil {
-			http.Error(w, `{"error": "Invalid movie ID"}`, http.StatusBadRequest)
+			response.Error(w, r, http.StatusBadRequest, response.CodeBadRequest, "Invalid movie ID")
 			return
 		}
 		reviews, err = h.reviewRepo.GetMovieReviews(r.Context(), movieID)
 	} else {
 		// Get all public reviews or implement pagination
-		http.Error(w, `{"error": "movie_id parameter is required"}`, http.StatusBadRequest)
+		response.Error(w, r, http.StatusBadRequest, response.CodeBadRequest, "movie_id parameter is required")
 		return
 	}
 
 	if err != nil {
 		log.Printf("Error getting reviews for API: %v", err)
-		http.Error(w, `{"error": "Failed to fetch reviews"}`, http.StatusInternalServerError)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch reviews")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(reviews)
+	response.JSON(w, http.StatusOK, reviews)
 }
 
 // HandleAPICreateReview creates a review (API endpoint)
 func (h *Handler) HandleAPICreateReview(w http.ResponseWriter, r *http.Request) {
-	userIDStr := h.sessionManager.GetString(r.Context(), "userID")
+	userIDStr, _ := r.Context().Value(middlewaree.UserIDKey).(string)
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		http.Error(w, `{"error": "Unauthorized"}`, http.StatusUnauthorized)
+		response.Error(w, r, http.StatusUnauthorized, response.CodeUnauthorized, "Unauthorized")
 		return
 	}
 
@@ -111,18 +126,16 @@ func (h *Handler) HandleAPICreateReview(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		response.Error(w, r, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request body")
 		return
 	}
 
+	v := validation.New()
 	movieID, err := uuid.Parse(request.MovieID)
-	if err != nil {
-		http.Error(w, `{"error": "Invalid movie ID"}`, http.StatusBadRequest)
-		return
-	}
-
-	if request.Rating < 1 || request.Rating > 10 {
-		http.Error(w, `{"error": "Rating must be between 1 and 10"}`, http.StatusBadRequest)
+	v.Check(err == nil, "movie_id", "must be a valid movie ID")
+	validateReview(v, &request.Rating, request.Title, request.Content)
+	if !v.Valid() {
+		response.ValidationError(w, r, v.Errors)
 		return
 	}
 
@@ -139,11 +152,9 @@ func (h *Handler) HandleAPICreateReview(w http.ResponseWriter, r *http.Request)
 	review, err := h.reviewRepo.CreateReview(r.Context(), params)
 	if err != nil {
 		log.Printf("Error creating review via API: %v", err)
-		http.Error(w, `{"error": "Failed to create review"}`, http.StatusInternalServerError)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to create review")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(review)
+	response.JSON(w, http.StatusCreated, review)
 }
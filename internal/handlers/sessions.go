@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"battleNet/models"
+	"battleNet/templates"
+
+	"github.com/google/uuid"
+)
+
+// HandleSessionsPage - rodo vartotojo aktyvias sesijas
+func (h *Handler) HandleSessionsPage(w http.ResponseWriter, r *http.Request) {
+	email := h.sessionManager.GetString(r.Context(), "email")
+	role := h.sessionManager.GetString(r.Context(), "role")
+
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil {
+		http.Error(w, "Invalid session", http.StatusBadRequest)
+		return
+	}
+
+	sessions, err := h.sessionStore.ListByUser(r.Context(), userID, h.sessionManager.Token(r.Context()))
+	if err != nil {
+		log.Printf("Error getting sessions for user %s: %v", userID, err)
+		sessions = []models.UserSession{}
+	}
+
+	flash := templates.Flash{
+		Success: h.sessionManager.PopString(r.Context(), "flash_success"),
+		Error:   h.sessionManager.PopString(r.Context(), "flash_error"),
+	}
+
+	component := templates.SessionsPage(email, role, sessions, flash)
+	component.Render(r.Context(), w)
+}
+
+// HandleRevokeSession - atjungia pasirinktą sesiją
+func (h *Handler) HandleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil {
+		http.Error(w, "Invalid session", http.StatusBadRequest)
+		return
+	}
+
+	sessionID, err := uuid.Parse(r.FormValue("session_id"))
+	if err != nil {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return
+	}
+
+	// Dabartinės sesijos atšaukimas = atsijungimas
+	if r.FormValue("current") == "true" {
+		h.HandleLogout(w, r)
+		return
+	}
+
+	deleted, err := h.sessionStore.DeleteByID(r.Context(), userID, sessionID)
+	switch {
+	case err != nil:
+		log.Printf("Error revoking session %s: %v", sessionID, err)
+		h.sessionManager.Put(r.Context(), "flash_error", "Failed to sign out session")
+	case !deleted:
+		h.sessionManager.Put(r.Context(), "flash_error", "Session not found")
+	default:
+		log.Printf("User %s revoked session %s", userID, sessionID)
+		h.sessionManager.Put(r.Context(), "flash_success", "Session signed out")
+	}
+
+	http.Redirect(w, r, "/profile/sessions", http.StatusSeeOther)
+}
+
+// HandleRevokeOtherSessions - atjungia visas sesijas, išskyrus dabartinę
+func (h *Handler) HandleRevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil {
+		http.Error(w, "Invalid session", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.sessionStore.DeleteByUser(r.Context(), userID, h.sessionManager.Token(r.Context()))
+	if err != nil {
+		log.Printf("Error revoking sessions for user %s: %v", userID, err)
+		h.sessionManager.Put(r.Context(), "flash_error", "Failed to sign out other sessions")
+	} else {
+		log.Printf("User %s signed out %d other sessions", userID, deleted)
+		h.sessionManager.Put(r.Context(), "flash_success", "Signed out of all other sessions")
+	}
+
+	http.Redirect(w, r, "/profile/sessions", http.StatusSeeOther)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"battleNet/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const maxSuspensionReasonLength = 500
+
+// loadLoginUser grąžina aktyvų vartotoją pagal email, o jei paskyra
+// suspenduota - suspensiją (slaptažodį tikrina kviečiantysis). Pasibaigusi
+// suspensija nuimama iškart, nelaukiant background patikrinimo.
+func (h *Handler) loadLoginUser(ctx context.Context, email string) (*models.User, *models.SuspendedUser, error) {
+	user, err := h.userRepo.GetUserByEmail(ctx, email)
+	if err == nil || !errors.Is(err, pgx.ErrNoRows) {
+		return user, nil, err
+	}
+
+	suspended, err := h.suspensionRepo.GetSuspendedUserByEmail(ctx, email)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if suspended.Suspension.Expired() {
+		if err := h.suspensionRepo.Lift(ctx, suspended.User.UserID, nil); err != nil {
+			return nil, nil, err
+		}
+		log.Printf("Suspension of %s expired and was lifted", email)
+		user, err := h.userRepo.GetUserByEmail(ctx, email)
+		return user, nil, err
+	}
+
+	return nil, suspended, nil
+}
+
+// suspensionMessage - pranešimas suspenduotam vartotojui prisijungimo metu
+func suspensionMessage(suspension models.UserSuspension) string {
+	message := "Your account has been suspended"
+	if suspension.ExpiresAt != nil {
+		message += " until " + suspension.ExpiresAt.Local().Format("2006-01-02 15:04")
+	}
+	return message + ". Reason: " + suspension.Reason
+}
+
+// parseSuspensionExpiry - suspensijos trukmė dienomis ("" arba "0" - neterminuota)
+func parseSuspensionExpiry(days string) (*time.Time, error) {
+	if days == "" || days == "0" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(days)
+	if err != nil || n < 0 || n > 3650 {
+		return nil, errors.New("invalid suspension duration")
+	}
+	expiresAt := time.Now().AddDate(0, 0, n)
+	return &expiresAt, nil
+}
+
+// HandleModeratorReactivateUser - nuima suspensiją
+func (h *Handler) HandleModeratorReactivateUser(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := uuid.Parse(r.FormValue("user_id"))
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	suspended, err := h.suspensionRepo.GetSuspendedUser(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "Suspended user not found", http.StatusNotFound)
+		return
+	}
+	if !h.policy.Covers(h.sessionManager.GetString(r.Context(), "role"), suspended.User.Role) {
+		http.Error(w, "You can't reactivate a user with a higher role", http.StatusForbidden)
+		return
+	}
+
+	moderatorID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil {
+		http.Error(w, "Invalid session", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.suspensionRepo.Lift(r.Context(), userID, &moderatorID); err != nil {
+		log.Printf("Error reactivating user %s: %v", userID, err)
+		http.Error(w, "Failed to reactivate user", http.StatusInternalServerError)
+		return
+	}
+	h.recordAudit(r, auditUserReactivate, "user", userID.String(),
+		map[string]any{"is_active": false, "suspension_reason": suspended.Suspension.Reason},
+		map[string]any{"is_active": true})
+
+	http.Redirect(w, r, "/moderator/users", http.StatusSeeOther)
+}
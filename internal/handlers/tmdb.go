@@ -1,8 +1,8 @@
 package handlers
 
 import (
+	"battleNet/internal/response"
 	"battleNet/models"
-	"encoding/json"
 	"log"
 	"net/http"
 	"strconv"
@@ -72,12 +72,6 @@ func (h *Handler) HandleImportMovie(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	role := h.sessionManager.GetString(r.Context(), "role")
-	if role != "admin" && role != "moderator" {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
-	}
-
 	tmdbMovie, err := h.tmdbClient.GetMovieDetails(r.Context(), tmdbID)
 	if err != nil {
 		log.Printf("Error getting movie from TMDB: %v", err)
@@ -87,6 +81,14 @@ func (h *Handler) HandleImportMovie(w http.ResponseWriter, r *http.Request) {
 
 	movie := convertToOurMovieModel(tmdbMovie)
 
+	// Žanrų klaida neturi nutraukti importo - filmas išsaugomas be jų
+	genres, err := h.movieRepo.EnsureGenres(r.Context(), movie.GenreNames())
+	if err != nil {
+		log.Printf("Error loading genres of imported movie %d: %v", tmdbID, err)
+		genres = []models.Genre{}
+	}
+	movie.Genres = genres
+
 	err = h.movieRepo.CreateMovie(r.Context(), &movie)
 	if err != nil {
 		log.Printf("Error importing movie: %v", err)
@@ -94,6 +96,14 @@ func (h *Handler) HandleImportMovie(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if tmdbMovie.Credits != nil {
+		credits := creditsFromTMDB(tmdbMovie.Credits)
+		if err := h.personRepo.SaveMovieCredits(r.Context(), movie.MovieID, credits); err != nil {
+			log.Printf("Error saving credits of imported movie %s: %v", movie.MovieID, err)
+		}
+	}
+	h.recordAudit(r, auditMovieImport, "movie", movie.MovieID.String(), nil, movie)
+
 	http.Redirect(w, r, "/admin/movies", http.StatusSeeOther)
 }
 
@@ -120,12 +130,11 @@ func (h *Handler) HandleAPISearchMovies(w http.ResponseWriter, r *http.Request)
 
 	if err != nil {
 		log.Printf("Error in API search: %v", err)
-		http.Error(w, `{"error": "Failed to search movies"}`, http.StatusInternalServerError)
+		response.Error(w, r, http.StatusBadGateway, response.CodeUpstream, "Failed to search movies")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	response.JSON(w, http.StatusOK, result)
 }
 
 // convertToOurMovieModel - konvertuoja TMDB filmą į mūsų DB modelį
@@ -144,10 +153,46 @@ func convertToOurMovieModel(tmdbMovie *tmdb.TMDBMovie) models.Movie {
 		Status:       stringPtr(tmdbMovie.Status),
 		ImdbID:       stringPtr(tmdbMovie.ImdbID),
 		CreatedAt:    time.Now(),
+		Genres:       []models.Genre{},
+	}
+	// Žanrai kol kas tik su pavadinimu - ID priskiria EnsureGenres
+	for _, genre := range tmdbMovie.Genres {
+		movie.Genres = append(movie.Genres, models.Genre{Name: genre.Name})
 	}
 	return movie
 }
 
+// creditsFromTMDB - TMDB aktoriai ir komanda mūsų modeliu (person_id priskiria SaveMovieCredits)
+func creditsFromTMDB(credits *tmdb.Credits) models.MovieCredits {
+	result := models.MovieCredits{
+		Cast: make([]models.CastCredit, 0, len(credits.Cast)),
+		Crew: make([]models.CrewCredit, 0, len(credits.Crew)),
+	}
+	for _, member := range credits.Cast {
+		result.Cast = append(result.Cast, models.CastCredit{
+			Person:    tmdbPerson(member.ID, member.Name, member.ProfilePath),
+			Character: member.Character,
+			Order:     member.Order,
+		})
+	}
+	for _, member := range credits.Crew {
+		result.Crew = append(result.Crew, models.CrewCredit{
+			Person:     tmdbPerson(member.ID, member.Name, member.ProfilePath),
+			Department: member.Department,
+			Job:        member.Job,
+		})
+	}
+	return result
+}
+
+func tmdbPerson(tmdbID int, name, profilePath string) models.Person {
+	person := models.Person{TmdbID: &tmdbID, Name: name}
+	if profilePath != "" {
+		person.ProfilePath = stringPtr(formatProfileURL(profilePath))
+	}
+	return person
+}
+
 // floatPtr - konvertuoja float64 į *float64
 func floatPtr(f float64) *float64 {
 	if f == 0 {
@@ -199,3 +244,11 @@ func formatPosterURL(path string) string {
 	}
 	return "https://image.tmdb.org/t/p/w500" + path
 }
+
+// formatProfileURL - asmens nuotrauka (mažesnis dydis nei plakatams)
+func formatProfileURL(path string) string {
+	if path == "" {
+		return ""
+	}
+	return "https://image.tmdb.org/t/p/w185" + path
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"battleNet/middlewaree"
+	"battleNet/models"
+	"battleNet/templates"
+
+	"github.com/google/uuid"
+)
+
+const personalAccessTokenPrefix = "bnpat_"
+
+// HandleTokensPage - rodo vartotojo personal access token'us
+func (h *Handler) HandleTokensPage(w http.ResponseWriter, r *http.Request) {
+	h.renderTokensPage(w, r, "", "")
+}
+
+// HandleCreateToken - sukuria naują personal access token'ą ir parodo jį vieną kartą
+func (h *Handler) HandleCreateToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil {
+		http.Error(w, "Invalid session", http.StatusBadRequest)
+		return
+	}
+	role := h.sessionManager.GetString(r.Context(), "role")
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" || len(name) > 100 {
+		h.renderTokensPage(w, r, "", "Token name is required (max 100 characters)")
+		return
+	}
+
+	scopes := r.Form["scopes"]
+	if len(scopes) == 0 {
+		h.renderTokensPage(w, r, "", "Select at least one scope")
+		return
+	}
+	for _, scope := range scopes {
+		if !h.scopeAllowed(role, scope) {
+			h.renderTokensPage(w, r, "", "You can't grant the "+scope+" scope")
+			return
+		}
+	}
+
+	var expiresAt *time.Time
+	if days := r.FormValue("expires_in_days"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			h.renderTokensPage(w, r, "", "Invalid expiration")
+			return
+		}
+		t := time.Now().AddDate(0, 0, n)
+		expiresAt = &t
+	}
+
+	secret, err := randomToken(32)
+	if err != nil {
+		log.Printf("Failed to generate personal access token: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	plaintext := personalAccessTokenPrefix + secret
+
+	token := &models.PersonalAccessToken{
+		UserID:      userID,
+		Name:        name,
+		TokenPrefix: plaintext[:len(personalAccessTokenPrefix)+6],
+		Scopes:      scopes,
+		ExpiresAt:   expiresAt,
+	}
+	if err := h.patRepo.CreateToken(r.Context(), token, hashToken(plaintext)); err != nil {
+		log.Printf("Failed to create personal access token for user %s: %v", userID, err)
+		h.renderTokensPage(w, r, "", "Failed to create token")
+		return
+	}
+
+	log.Printf("User %s created personal access token %s (%s)", userID, token.TokenID, strings.Join(scopes, ","))
+	h.renderTokensPage(w, r, plaintext, "")
+}
+
+// HandleRevokeToken - ištrina personal access token'ą
+func (h *Handler) HandleRevokeToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil {
+		http.Error(w, "Invalid session", http.StatusBadRequest)
+		return
+	}
+
+	tokenID, err := uuid.Parse(r.FormValue("token_id"))
+	if err != nil {
+		http.Error(w, "Invalid token ID", http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := h.patRepo.DeleteToken(r.Context(), userID, tokenID)
+	switch {
+	case err != nil:
+		log.Printf("Error revoking personal access token %s: %v", tokenID, err)
+		h.sessionManager.Put(r.Context(), "flash_error", "Failed to revoke token")
+	case !deleted:
+		h.sessionManager.Put(r.Context(), "flash_error", "Token not found")
+	default:
+		log.Printf("User %s revoked personal access token %s", userID, tokenID)
+		h.sessionManager.Put(r.Context(), "flash_success", "Token revoked")
+	}
+
+	http.Redirect(w, r, "/profile/tokens", http.StatusSeeOther)
+}
+
+// renderTokensPage - newToken rodomas tik iškart po sukūrimo
+func (h *Handler) renderTokensPage(w http.ResponseWriter, r *http.Request, newToken, errorMsg string) {
+	email := h.sessionManager.GetString(r.Context(), "email")
+	role := h.sessionManager.GetString(r.Context(), "role")
+
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil {
+		http.Error(w, "Invalid session", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := h.patRepo.GetUserTokens(r.Context(), userID)
+	if err != nil {
+		log.Printf("Error getting personal access tokens for user %s: %v", userID, err)
+		tokens = []models.PersonalAccessToken{}
+	}
+
+	flash := templates.Flash{
+		Success: h.sessionManager.PopString(r.Context(), "flash_success"),
+		Error:   h.sessionManager.PopString(r.Context(), "flash_error"),
+	}
+	if errorMsg != "" {
+		flash.Error = errorMsg
+	}
+
+	component := templates.TokensPage(email, role, tokens, h.availableScopes(role), newToken, flash)
+	component.Render(r.Context(), w)
+}
+
+// authenticatePersonalAccessToken - Bearer autentifikacija personal access token'u
+func (h *Handler) authenticatePersonalAccessToken(ctx context.Context, token string) (*middlewaree.Principal, error) {
+	user, scopes, err := h.patRepo.Authenticate(ctx, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	// Scope'ai, kurių vartotojo rolė nebeleidžia, ignoruojami
+	granted := []string{}
+	for _, scope := range scopes {
+		if h.scopeAllowed(user.Role, scope) {
+			granted = append(granted, scope)
+		}
+	}
+
+	return &middlewaree.Principal{
+		UserID:        user.UserID.String(),
+		Email:         user.Email,
+		Role:          user.Role,
+		EmailVerified: user.EmailVerified,
+		// Su PAT antrasis faktorius nepateikiamas, todėl RequireTwoFactorAPI jį atmeta
+		TwoFactor: false,
+		Scopes:    granted,
+	}, nil
+}
+
+// availableScopes grąžina scope'us, kuriuos rolė gali suteikti token'ui. Kai
+// REQUIRE_STAFF_2FA įjungtas, personalo scope'ai neleidžiami: jų maršrutams
+// reikia 2FA, o PAT jo neturi.
+func (h *Handler) availableScopes(role string) []models.TokenScope {
+	var scopes []models.TokenScope
+	for _, scope := range models.TokenScopes {
+		if scope.Permission != "" && h.requireStaffTwoFactor {
+			continue
+		}
+		if scope.Permission == "" || h.policy.Can(role, scope.Permission) {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
+
+func (h *Handler) scopeAllowed(role, name string) bool {
+	return slices.ContainsFunc(h.availableScopes(role), func(scope models.TokenScope) bool {
+		return scope.Name == name
+	})
+}
@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"battleNet/auth/totp"
+	"battleNet/models"
+	"battleNet/templates"
+
+	"github.com/a-h/templ"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	twoFactorIssuer       = "BattleNet"
+	pendingTwoFactorTTL   = 5 * time.Minute
+	maxTwoFactorAttempts  = 5
+	recoveryCodeCount     = 10
+	recoveryCodeHalfChars = 5
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// completeLogin užbaigia prisijungimą po slaptažodžio ar OAuth patikrinimo.
+// Jei vartotojas turi 2FA, sesija dar nepradedama - nukreipiama į /login/2fa.
+func (h *Handler) completeLogin(w http.ResponseWriter, r *http.Request, user *models.User, method string) {
+	enabled, err := h.twoFactorRepo.IsEnabled(r.Context(), user.UserID)
+	if err != nil {
+		log.Printf("Failed to check 2FA for user %s: %v", user.UserID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if enabled {
+		if err := h.sessionManager.RenewToken(r.Context()); err != nil {
+			log.Printf("Failed to renew session token: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		h.sessionManager.Put(r.Context(), "pending_2fa_user_id", user.UserID.String())
+		h.sessionManager.Put(r.Context(), "pending_2fa_expires", time.Now().Add(pendingTwoFactorTTL).Unix())
+		h.sessionManager.Put(r.Context(), "pending_2fa_attempts", 0)
+		http.Redirect(w, r, "/login/2fa", http.StatusSeeOther)
+		return
+	}
+
+	h.finishLogin(w, r, user, method, false)
+}
+
+// finishLogin pradeda vartotojo sesiją ir nukreipia į dashboard
+func (h *Handler) finishLogin(w http.ResponseWriter, r *http.Request, user *models.User, method string, twoFactor bool) {
+	h.recordLoginSuccess(r.Context(), r, user)
+
+	if err := h.userRepo.UpdateLastLogin(r.Context(), user.UserID); err != nil {
+		log.Printf("Failed to update last login for user %s: %v", user.UserID, err)
+	}
+
+	if err := h.startUserSession(r, user); err != nil {
+		log.Printf("Failed to start session for user %s: %v", user.Email, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if twoFactor {
+		h.sessionManager.Put(r.Context(), "two_factor", true)
+	}
+
+	log.Printf("User logged in with %s: %s (role: %s, 2fa: %t)", method, user.Email, user.Role, twoFactor)
+	http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+}
+
+// HandleTwoFactorLoginPage - antrasis prisijungimo žingsnis
+func (h *Handler) HandleTwoFactorLoginPage(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.pendingTwoFactorUser(r); !ok {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	component := templates.TwoFactorLoginPage("")
+	component.Render(r.Context(), w)
+}
+
+// HandleTwoFactorLogin - tikrina TOTP arba atkūrimo kodą ir užbaigia prisijungimą
+func (h *Handler) HandleTwoFactorLogin(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := h.pendingTwoFactorUser(r)
+	if !ok {
+		h.renderLogin(w, r, "Your sign-in attempt expired, please log in again")
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(r.Context(), userID)
+	if err != nil || !user.IsActive {
+		h.clearPendingTwoFactor(r)
+		h.renderLogin(w, r, "Invalid email or password")
+		return
+	}
+
+	if remaining := h.loginLockedFor(r.Context(), user.Email, clientIP(r)); remaining > 0 {
+		h.clearPendingTwoFactor(r)
+		h.recordLoginAttempt(r.Context(), r, user.Email, &user.UserID, false, "locked")
+		h.renderLogin(w, r, lockoutMessage(remaining))
+		return
+	}
+
+	valid, err := h.verifySecondFactor(r.Context(), userID, r.FormValue("code"))
+	if err != nil {
+		log.Printf("Failed to verify 2FA code for user %s: %v", userID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if !valid {
+		h.recordLoginFailure(r.Context(), r, user.Email, &user.UserID, "invalid_2fa")
+		attempts := h.sessionManager.GetInt(r.Context(), "pending_2fa_attempts") + 1
+		log.Printf("Invalid 2FA code for user %s (attempt %d)", userID, attempts)
+		if attempts >= maxTwoFactorAttempts {
+			h.clearPendingTwoFactor(r)
+			h.renderLogin(w, r, "Too many invalid codes, please log in again")
+			return
+		}
+		h.sessionManager.Put(r.Context(), "pending_2fa_attempts", attempts)
+		component := templates.TwoFactorLoginPage("Invalid authentication code")
+		component.Render(r.Context(), w)
+		return
+	}
+
+	h.clearPendingTwoFactor(r)
+	h.finishLogin(w, r, user, "2fa", true)
+}
+
+// HandleTwoFactorPage - 2FA nustatymai profilyje: registracija arba būsena
+func (h *Handler) HandleTwoFactorPage(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil {
+		http.Error(w, "Invalid session", http.StatusBadRequest)
+		return
+	}
+
+	enabled, err := h.twoFactorRepo.IsEnabled(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to check 2FA for user %s: %v", userID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if enabled {
+		h.renderTwoFactorPage(w, r, templates.TwoFactorSettings{Enabled: true}, "")
+		return
+	}
+
+	// Kiekvienas apsilankymas pradeda registraciją iš naujo su nauju secret'u
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		log.Printf("Failed to generate TOTP secret: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if _, err := h.twoFactorRepo.StartEnrollment(r.Context(), userID, secret); err != nil {
+		log.Printf("Failed to start 2FA enrollment for user %s: %v", userID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.renderTwoFactorPage(w, r, h.enrollmentSettings(r, secret), "")
+}
+
+// HandleEnableTwoFactor - patvirtina registraciją pirmuoju kodu ir parodo atkūrimo kodus
+func (h *Handler) HandleEnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil {
+		http.Error(w, "Invalid session", http.StatusBadRequest)
+		return
+	}
+
+	secret, enabled, err := h.twoFactorRepo.GetSecret(r.Context(), userID)
+	if errors.Is(err, pgx.ErrNoRows) || enabled {
+		http.Redirect(w, r, "/profile/2fa", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to load 2FA secret for user %s: %v", userID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	step, ok := totp.Validate(secret, r.FormValue("code"), time.Now())
+	if !ok {
+		h.renderTwoFactorPage(w, r, h.enrollmentSettings(r, secret), "Invalid code - check your device's clock and try again")
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		log.Printf("Failed to generate recovery codes: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.twoFactorRepo.Enable(r.Context(), userID, step, hashes); err != nil {
+		log.Printf("Failed to enable 2FA for user %s: %v", userID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// Vartotojas ką tik įrodė, kad turi antrąjį faktorių
+	h.sessionManager.Put(r.Context(), "two_factor", true)
+
+	log.Printf("User %s enabled 2FA", userID)
+	h.renderTwoFactorPage(w, r, templates.TwoFactorSettings{Enabled: true, RecoveryCodes: codes}, "")
+}
+
+// HandleDisableTwoFactor - išjungia 2FA (reikia galiojančio kodo)
+func (h *Handler) HandleDisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil {
+		http.Error(w, "Invalid session", http.StatusBadRequest)
+		return
+	}
+
+	if h.twoFactorRequired(h.sessionManager.GetString(r.Context(), "role")) {
+		h.sessionManager.Put(r.Context(), "flash_error", "Two-factor authentication is mandatory for your role")
+		http.Redirect(w, r, "/profile/2fa", http.StatusSeeOther)
+		return
+	}
+
+	valid, err := h.verifySecondFactor(r.Context(), userID, r.FormValue("code"))
+	if err != nil {
+		log.Printf("Failed to verify 2FA code for user %s: %v", userID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !valid {
+		h.sessionManager.Put(r.Context(), "flash_error", "Invalid authentication code")
+		http.Redirect(w, r, "/profile/2fa", http.StatusSeeOther)
+		return
+	}
+
+	if err := h.twoFactorRepo.Disable(r.Context(), userID); err != nil {
+		log.Printf("Failed to disable 2FA for user %s: %v", userID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	h.sessionManager.Put(r.Context(), "two_factor", false)
+	h.sessionManager.Put(r.Context(), "flash_success", "Two-factor authentication disabled")
+
+	log.Printf("User %s disabled 2FA", userID)
+	http.Redirect(w, r, "/profile", http.StatusSeeOther)
+}
+
+// HandleRegenerateRecoveryCodes - sukuria naujus atkūrimo kodus (seni nebegalioja)
+func (h *Handler) HandleRegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil {
+		http.Error(w, "Invalid session", http.StatusBadRequest)
+		return
+	}
+
+	valid, err := h.verifySecondFactor(r.Context(), userID, r.FormValue("code"))
+	if err != nil {
+		log.Printf("Failed to verify 2FA code for user %s: %v", userID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !valid {
+		h.sessionManager.Put(r.Context(), "flash_error", "Invalid authentication code")
+		http.Redirect(w, r, "/profile/2fa", http.StatusSeeOther)
+		return
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		log.Printf("Failed to generate recovery codes: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.twoFactorRepo.RegenerateRecoveryCodes(r.Context(), userID, hashes); err != nil {
+		log.Printf("Failed to regenerate recovery codes for user %s: %v", userID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("User %s regenerated 2FA recovery codes", userID)
+	h.renderTwoFactorPage(w, r, templates.TwoFactorSettings{Enabled: true, RecoveryCodes: codes}, "")
+}
+
+// verifySecondFactor priima TOTP kodą (kiekvieną tik kartą) arba nepanaudotą atkūrimo kodą
+func (h *Handler) verifySecondFactor(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	secret, enabled, err := h.twoFactorRepo.GetSecret(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil || !enabled {
+		return false, err
+	}
+
+	if step, ok := totp.Validate(secret, code, time.Now()); ok {
+		return h.twoFactorRepo.ClaimStep(ctx, userID, step)
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != 2*recoveryCodeHalfChars {
+		return false, nil
+	}
+	return h.twoFactorRepo.UseRecoveryCode(ctx, userID, hashToken(normalized))
+}
+
+// twoFactorRequired - ar rolei 2FA privalomas pagal konfigūraciją
+func (h *Handler) twoFactorRequired(role string) bool {
+	return h.requireStaffTwoFactor && (role == "admin" || role == "moderator")
+}
+
+func (h *Handler) pendingTwoFactorUser(r *http.Request) (uuid.UUID, bool) {
+	if time.Now().Unix() > h.sessionManager.GetInt64(r.Context(), "pending_2fa_expires") {
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "pending_2fa_user_id"))
+	return userID, err == nil
+}
+
+func (h *Handler) clearPendingTwoFactor(r *http.Request) {
+	h.sessionManager.Remove(r.Context(), "pending_2fa_user_id")
+	h.sessionManager.Remove(r.Context(), "pending_2fa_expires")
+	h.sessionManager.Remove(r.Context(), "pending_2fa_attempts")
+}
+
+func (h *Handler) enrollmentSettings(r *http.Request, secret string) templates.TwoFactorSettings {
+	email := h.sessionManager.GetString(r.Context(), "email")
+	settings := templates.TwoFactorSettings{Secret: secret}
+
+	// QR kodas generuojamas serveryje - slaptas raktas neperduodamas jokiai trečiajai šaliai
+	png, err := qrcode.Encode(totp.KeyURI(twoFactorIssuer, email, secret), qrcode.Medium, 200)
+	if err != nil {
+		log.Printf("Error generating 2FA QR code: %v", err)
+		return settings
+	}
+	settings.QRCode = templ.SafeURL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png))
+	return settings
+}
+
+func (h *Handler) renderTwoFactorPage(w http.ResponseWriter, r *http.Request, settings templates.TwoFactorSettings, errorMsg string) {
+	email := h.sessionManager.GetString(r.Context(), "email")
+	role := h.sessionManager.GetString(r.Context(), "role")
+	settings.Required = h.twoFactorRequired(role)
+
+	if userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID")); err == nil && settings.Enabled {
+		if remaining, err := h.twoFactorRepo.CountRecoveryCodes(r.Context(), userID); err == nil {
+			settings.RemainingCodes = remaining
+		}
+	}
+
+	flash := templates.Flash{
+		Success: h.sessionManager.PopString(r.Context(), "flash_success"),
+		Error:   h.sessionManager.PopString(r.Context(), "flash_error"),
+	}
+	if errorMsg != "" {
+		flash.Error = errorMsg
+	}
+
+	component := templates.TwoFactorPage(email, role, settings, flash)
+	component.Render(r.Context(), w)
+}
+
+// generateRecoveryCodes grąžina kodus rodymui (xxxxx-xxxxx) ir jų hash'us saugojimui
+func generateRecoveryCodes() (codes, hashes []string, err error) {
+	for range recoveryCodeCount {
+		b := make([]byte, 2*recoveryCodeHalfChars*5/8)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		codes = append(codes, raw[:recoveryCodeHalfChars]+"-"+raw[recoveryCodeHalfChars:])
+		hashes = append(hashes, hashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
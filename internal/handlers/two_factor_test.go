@@ -0,0 +1,51 @@
+package handlers_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"battleNet/auth/totp"
+	"battleNet/repository"
+)
+
+// TOTP kodo žingsnis priimamas tik vieną kartą; ankstesni žingsniai po jo - irgi ne
+func TestTwoFactorClaimStepRejectsReplay(t *testing.T) {
+	app := newTestApp(t)
+	user := app.createUser(t, "user")
+	repo := repository.NewTwoFactorRepository(app.db.Pool)
+	ctx := context.Background()
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.StartEnrollment(ctx, user.UserID, secret); err != nil {
+		t.Fatalf("start enrollment: %v", err)
+	}
+	const enrolledStep = 1000
+	recoveryCodes := []string{strings.Repeat("a", 64)}
+	if err := repo.Enable(ctx, user.UserID, enrolledStep, recoveryCodes); err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+
+	claims := []struct {
+		step int64
+		want bool
+	}{
+		{enrolledStep, false},     // patvirtinimo kodas jau panaudotas
+		{enrolledStep + 1, true},  // kitas žingsnis
+		{enrolledStep + 1, false}, // tas pats kodas antrą kartą
+		{enrolledStep, false},     // senesnis žingsnis
+		{enrolledStep + 2, true},
+	}
+	for _, c := range claims {
+		ok, err := repo.ClaimStep(ctx, user.UserID, c.step)
+		if err != nil {
+			t.Fatalf("claim step %d: %v", c.step, err)
+		}
+		if ok != c.want {
+			t.Errorf("ClaimStep(%d) = %v, want %v", c.step, ok, c.want)
+		}
+	}
+}
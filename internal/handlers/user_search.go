@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"battleNet/models"
+)
+
+const (
+	usersPerPage    = 25
+	maxUsersPerPage = 100
+)
+
+// parseUserFilter nuskaito paiešką iš query: q, role, status (active|suspended),
+// verified (true|false), last_login_from / last_login_to (YYYY-MM-DD, "to" imtinai),
+// sort, order (asc|desc), page ir per_page. Grąžina filtrą ir puslapio numerį.
+// Nežinomas sort pakeičiamas numatytuoju created_at (jau ir grąžinant klaidą).
+func parseUserFilter(query url.Values) (models.UserFilter, int, error) {
+	filter := models.UserFilter{
+		Query:      query.Get("q"),
+		Role:       query.Get("role"),
+		Sort:       query.Get("sort"),
+		Descending: query.Get("order") != "asc",
+		Limit:      usersPerPage,
+	}
+
+	if !slices.Contains(models.UserSorts, filter.Sort) {
+		filter.Sort = "created_at"
+	}
+
+	switch query.Get("status") {
+	case "":
+	case "active":
+		active := true
+		filter.Active = &active
+	case "suspended":
+		active := false
+		filter.Active = &active
+	default:
+		return filter, 1, errors.New("invalid status")
+	}
+
+	if verified := query.Get("verified"); verified != "" {
+		v, err := strconv.ParseBool(verified)
+		if err != nil {
+			return filter, 1, errors.New("invalid verified value")
+		}
+		filter.EmailVerified = &v
+	}
+
+	for _, param := range []struct {
+		name     string
+		target   **time.Time
+		endOfDay bool
+	}{
+		{"last_login_from", &filter.LastLoginFrom, false},
+		{"last_login_to", &filter.LastLoginTo, true},
+	} {
+		value := query.Get(param.name)
+		if value == "" {
+			continue
+		}
+		t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return filter, 1, errors.New("invalid " + param.name)
+		}
+		if param.endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		*param.target = &t
+	}
+
+	if perPage := query.Get("per_page"); perPage != "" {
+		n, err := strconv.Atoi(perPage)
+		if err != nil || n <= 0 {
+			return filter, 1, errors.New("invalid per_page")
+		}
+		filter.Limit = min(n, maxUsersPerPage)
+	}
+
+	page := 1
+	if p := query.Get("page"); p != "" {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 1 {
+			return filter, 1, errors.New("invalid page")
+		}
+		page = n
+	}
+	filter.Offset = (page - 1) * filter.Limit
+
+	return filter, page, nil
+}
+
+// totalPages - puslapių skaičius (bent 1)
+func totalPages(total, perPage int) int {
+	if total == 0 {
+		return 1
+	}
+	return (total + perPage - 1) / perPage
+}
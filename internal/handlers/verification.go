@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"battleNet/auth/signed"
+	"battleNet/mail"
+	"battleNet/models"
+	"battleNet/templates"
+
+	"github.com/google/uuid"
+)
+
+const (
+	emailVerificationPurpose   = "email-verification"
+	emailVerificationTTL       = 48 * time.Hour
+	verificationResendInterval = 5 * time.Minute
+)
+
+var errVerificationThrottled = errors.New("verification email sent recently")
+
+// sendVerificationEmail išsiunčia patvirtinimo nuorodą, jei neseniai nebuvo siųsta
+func (h *Handler) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	claimed, err := h.userRepo.ClaimVerificationSend(ctx, user.UserID, verificationResendInterval)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return errVerificationThrottled
+	}
+
+	token := h.signer.Sign(emailVerificationPurpose, user.UserID.String()+"|"+user.Email, emailVerificationTTL)
+	link := h.appBaseURL + "/verify-email?token=" + url.QueryEscape(token)
+
+	return h.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Confirm your BattleNet email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %d hours. If you did not create a BattleNet account, you can ignore this email.\n",
+			user.FirstName, link, int(emailVerificationTTL.Hours()),
+		),
+	})
+}
+
+// HandleVerifyEmail - patvirtina email pagal pasirašytą token'ą
+func (h *Handler) HandleVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	payload, err := h.signer.Verify(emailVerificationPurpose, r.URL.Query().Get("token"))
+	if err != nil {
+		message := "This verification link is invalid."
+		if errors.Is(err, signed.ErrExpiredToken) {
+			message = "This verification link has expired. Log in and request a new one from your profile."
+		}
+		templates.VerifyEmailPage(false, message).Render(r.Context(), w)
+		return
+	}
+
+	parts := strings.SplitN(payload, "|", 2)
+	userID, err := uuid.Parse(parts[0])
+	if err != nil || len(parts) != 2 {
+		templates.VerifyEmailPage(false, "This verification link is invalid.").Render(r.Context(), w)
+		return
+	}
+
+	verified, err := h.userRepo.MarkEmailVerified(r.Context(), userID, parts[1])
+	if err != nil {
+		log.Printf("Error verifying email for user %s: %v", userID, err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !verified {
+		templates.VerifyEmailPage(false, "This verification link is no longer valid for your account.").Render(r.Context(), w)
+		return
+	}
+
+	// Atnaujinti sesiją, jei patvirtina tas pats prisijungęs vartotojas
+	if h.sessionManager.GetString(r.Context(), "userID") == userID.String() {
+		h.sessionManager.Put(r.Context(), "email_verified", true)
+	}
+
+	log.Printf("Email verified for user %s", userID)
+	templates.VerifyEmailPage(true, "Your email address has been confirmed.").Render(r.Context(), w)
+}
+
+// HandleResendVerification - pakartotinai išsiunčia patvirtinimo laišką
+func (h *Handler) HandleResendVerification(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil {
+		http.Error(w, "Invalid session", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userRepo.GetUserByID(r.Context(), userID)
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	if user.EmailVerified {
+		h.sessionManager.Put(r.Context(), "email_verified", true)
+		h.sessionManager.Put(r.Context(), "flash_success", "Your email address is already confirmed")
+		http.Redirect(w, r, "/profile", http.StatusSeeOther)
+		return
+	}
+
+	err = h.sendVerificationEmail(r.Context(), user)
+	switch {
+	case errors.Is(err, errVerificationThrottled):
+		h.sessionManager.Put(r.Context(), "flash_error", "A verification email was sent recently. Please wait a few minutes before requesting another.")
+	case err != nil:
+		log.Printf("Error sending verification email to %s: %v", user.Email, err)
+		h.sessionManager.Put(r.Context(), "flash_error", "Failed to send verification email")
+	default:
+		h.sessionManager.Put(r.Context(), "flash_success", "Verification email sent to "+user.Email)
+	}
+
+	http.Redirect(w, r, "/profile", http.StatusSeeOther)
+}
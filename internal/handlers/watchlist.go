@@ -6,7 +6,8 @@ import (
 	"log"
 	"net/http"
 
-	//"battleNet/models"
+	"battleNet/internal/response"
+	"battleNet/middlewaree"
 	"battleNet/templates"
 
 	"github.com/go-chi/chi/v5"
@@ -84,30 +85,29 @@ func (h *Handler) HandleAddToWatchlist(w http.ResponseWriter, r *http.Request) {
 
 // HandleAPIWatchlist returns watchlist as JSON (API endpoint)
 func (h *Handler) HandleAPIWatchlist(w http.ResponseWriter, r *http.Request) {
-	userIDStr := h.sessionManager.GetString(r.Context(), "userID")
+	userIDStr, _ := r.Context().Value(middlewaree.UserIDKey).(string)
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		http.Error(w, `{"error": "Unauthorized"}`, http.StatusUnauthorized)
+		response.Error(w, r, http.StatusUnauthorized, response.CodeUnauthorized, "Unauthorized")
 		return
 	}
 
 	watchlist, err := h.watchlistRepo.GetUserWatchlist(r.Context(), userID)
 	if err != nil {
 		log.Printf("Error getting watchlist for API: %v", err)
-		http.Error(w, `{"error": "Failed to fetch watchlist"}`, http.StatusInternalServerError)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to fetch watchlist")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(watchlist)
+	response.JSON(w, http.StatusOK, watchlist)
 }
 
 // HandleAPIAddToWatchlist adds movie to watchlist (API endpoint)
 func (h *Handler) HandleAPIAddToWatchlist(w http.ResponseWriter, r *http.Request) {
-	userIDStr := h.sessionManager.GetString(r.Context(), "userID")
+	userIDStr, _ := r.Context().Value(middlewaree.UserIDKey).(string)
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		http.Error(w, `{"error": "Unauthorized"}`, http.StatusUnauthorized)
+		response.Error(w, r, http.StatusUnauthorized, response.CodeUnauthorized, "Unauthorized")
 		return
 	}
 
@@ -115,47 +115,72 @@ func (h *Handler) HandleAPIAddToWatchlist(w http.ResponseWriter, r *http.Request
 		MovieID string `json:"movie_id"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
-		http.Error(w, `{"error": "Invalid request body"}`, http.StatusBadRequest)
+		response.Error(w, r, http.StatusBadRequest, response.CodeInvalidBody, "Invalid request body")
 		return
 	}
 
 	movieID, err := uuid.Parse(request.MovieID)
 	if err != nil {
-		http.Error(w, `{"error": "Invalid movie ID"}`, http.StatusBadRequest)
+		response.Error(w, r, http.StatusBadRequest, response.CodeBadRequest, "Invalid movie ID")
 		return
 	}
 
 	item, err := h.watchlistRepo.AddToWatchlist(r.Context(), userID, movieID)
 	if err != nil {
 		log.Printf("Error adding to watchlist via API: %v", err)
-		http.Error(w, `{"error": "Failed to add to watchlist"}`, http.StatusInternalServerError)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to add to watchlist")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(item)
+	response.JSON(w, http.StatusOK, item)
 }
 
 // HandleAPIRemoveFromWatchlist removes movie from watchlist (API endpoint)
 func (h *Handler) HandleAPIRemoveFromWatchlist(w http.ResponseWriter, r *http.Request) {
-	userIDStr := h.sessionManager.GetString(r.Context(), "userID")
+	userIDStr, _ := r.Context().Value(middlewaree.UserIDKey).(string)
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
-		http.Error(w, `{"error": "Unauthorized"}`, http.StatusUnauthorized)
+		response.Error(w, r, http.StatusUnauthorized, response.CodeUnauthorized, "Unauthorized")
 		return
 	}
 
 	movieIDStr := chi.URLParam(r, "movieId")
 	movieID, err := uuid.Parse(movieIDStr)
 	if err != nil {
-		http.Error(w, `{"error": "Invalid movie ID"}`, http.StatusBadRequest)
+		response.Error(w, r, http.StatusBadRequest, response.CodeBadRequest, "Invalid movie ID")
+		return
+	}
+
+	if err := h.watchlistRepo.RemoveFromWatchlist(r.Context(), userID, movieID); err != nil {
+		log.Printf("Error removing from watchlist via API: %v", err)
+		response.Error(w, r, http.StatusInternalServerError, response.CodeInternal, "Failed to remove from watchlist")
 		return
 	}
 
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleHTMXRemoveFromWatchlist removes movie from watchlist (watchlist page, HTMX)
+func (h *Handler) HandleHTMXRemoveFromWatchlist(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(h.sessionManager.GetString(r.Context(), "userID"))
+	if err != nil {
+		http.Error(w, "Invalid user session", http.StatusUnauthorized)
+		return
+	}
+
+	movieIDStr := chi.URLParam(r, "movieId")
+	movieID, err := uuid.Parse(movieIDStr)
+	if err != nil {
+		http.Error(w, "Invalid movie ID", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html")
 	err = h.watchlistRepo.RemoveFromWatchlist(r.Context(), userID, movieID)
 	if err != nil {
-		log.Printf("Error removing from watchlist via API: %v", err)
-		w.Header().Set("Content-Type", "text/html")
+		log.Printf("Error removing from watchlist: %v", err)
+		// Kortelė paliekama - rodomas tik klaidos pranešimas
+		w.Header().Set("HX-Reswap", "none")
 		w.Write([]byte(`
 <div hx-swap-oob="beforeend:#toast-container">
     <div class="toast toast-error">
@@ -168,9 +193,8 @@ func (h *Handler) HandleAPIRemoveFromWatchlist(w http.ResponseWriter, r *http.Re
 	}
 
 	// Return both: the removed movie card and success toast
-	w.Header().Set("Content-Type", "text/html")
 	w.Write([]byte(`
-<div hx-swap-oob="delete:.movie-card[data-movie-id='` + movieIDStr + `']"></div>
+<div hx-swap-oob="delete:.movie-card[data-movie-id='` + movieID.String() + `']"></div>
 <div hx-swap-oob="beforeend:#toast-container">
     <div class="toast toast-success">
         Movie was successfully removed from watchlist
@@ -205,7 +229,6 @@ func (h *Handler) HandleRemoveFromWatchlist(w http.ResponseWriter, r *http.Reque
 	err = h.watchlistRepo.RemoveFromWatchlist(r.Context(), userID, movieID)
 	if err != nil {
 		log.Printf("Error removing from watchlist: %v", err)
-		// Galite grąžinti error puslapį arba redirect su flash message
 		http.Error(w, "Failed to remove from watchlist", http.StatusInternalServerError)
 		return
 	}
@@ -0,0 +1,142 @@
+// Package response writes /api/v1 responses. Errors are RFC 9457 problem
+// details (application/problem+json) carrying a stable machine-readable
+// code, optional per-field errors and the request ID, e.g.
+//
+//	{"type": "about:blank", "title": "Not Found", "status": 404,
+//	 "detail": "Movie not found", "code": "not_found",
+//	 "instance": "/api/v1/movies/…", "request_id": "host/abc-000001"}
+//
+// Clients should match on code; detail is human readable and may change.
+package response
+
+import (
+	"encoding/json"
+	"log"
+	"maps"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Klaidų kodai. Jie yra API kontrakto dalis - esamų nekeisti.
+const (
+	CodeBadRequest         = "bad_request"
+	CodeInvalidBody        = "invalid_body"
+	CodeValidation         = "validation_failed"
+	CodeUnauthorized       = "unauthorized"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeInvalidToken       = "invalid_token"
+	CodeOTPRequired        = "otp_required"
+	CodeInvalidOTP         = "invalid_otp"
+	CodeForbidden          = "forbidden"
+	CodeInsufficientScope  = "insufficient_scope"
+	CodeEmailNotVerified   = "email_not_verified"
+	CodeTwoFactorRequired  = "two_factor_required"
+	CodeAccountSuspended   = "account_suspended"
+	CodeCSRF               = "csrf_token_invalid"
+	CodeNotFound           = "not_found"
+	CodeMethodNotAllowed   = "method_not_allowed"
+	CodeConflict           = "conflict"
+	CodeTooManyRequests    = "too_many_requests"
+	CodeInternal           = "internal_error"
+	CodeUpstream           = "upstream_error"
+)
+
+// ContentTypeProblem - RFC 9457 media type
+const ContentTypeProblem = "application/problem+json"
+
+// Problem - RFC 9457 problem details su mūsų plėtiniais (code, request_id, errors).
+// Extensions įrašomi kaip papildomi top-level laukai.
+type Problem struct {
+	Type       string            `json:"type"`
+	Title      string            `json:"title"`
+	Status     int               `json:"status"`
+	Detail     string            `json:"detail,omitempty"`
+	Instance   string            `json:"instance,omitempty"`
+	Code       string            `json:"code"`
+	RequestID  string            `json:"request_id,omitempty"`
+	Errors     map[string]string `json:"errors,omitempty"`
+	Extensions map[string]any    `json:"-"`
+}
+
+// MarshalJSON sujungia standartinius laukus su Extensions (standartiniai laimi)
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	data, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+
+	fields := map[string]any{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	merged := maps.Clone(p.Extensions)
+	maps.Copy(merged, fields)
+	return json.Marshal(merged)
+}
+
+// JSON įrašo v kaip application/json su statusu
+func JSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to encode JSON response: %v", err)
+	}
+}
+
+// Error įrašo problem+json su statusu, kodu ir žmogui skirtu aprašymu
+func Error(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	Write(w, r, &Problem{Status: status, Code: code, Detail: detail})
+}
+
+// ValidationError - 422 su klaidomis pagal lauką
+func ValidationError(w http.ResponseWriter, r *http.Request, fields map[string]string) {
+	Write(w, r, &Problem{
+		Status: http.StatusUnprocessableEntity,
+		Code:   CodeValidation,
+		Detail: "One or more fields are invalid",
+		Errors: fields,
+	})
+}
+
+// Write užpildo trūkstamus laukus (type, title, instance, request_id) ir įrašo problemą
+func Write(w http.ResponseWriter, r *http.Request, p *Problem) {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	if p.Code == "" {
+		p.Code = CodeInternal
+	}
+	if r != nil {
+		if p.Instance == "" {
+			p.Instance = r.URL.Path
+		}
+		if p.RequestID == "" {
+			p.RequestID = middleware.GetReqID(r.Context())
+		}
+	}
+
+	w.Header().Set("Content-Type", ContentTypeProblem)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		log.Printf("Failed to encode problem response: %v", err)
+	}
+}
+
+// NotFound - chi NotFound handler'is API maršrutams
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Error(w, r, http.StatusNotFound, CodeNotFound, "No such API endpoint")
+}
+
+// MethodNotAllowed - chi MethodNotAllowed handler'is API maršrutams
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	Error(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "Method "+r.Method+" is not allowed for this endpoint")
+}
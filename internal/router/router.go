@@ -0,0 +1,225 @@
+// Package router sujungia aplikacijos maršrutus ir middleware. Atskirai nuo
+// main, kad routerį galėtų sukurti ir testai.
+package router
+
+import (
+	"net/http"
+
+	"battleNet/config"
+	"battleNet/internal/handlers"
+	"battleNet/internal/response"
+	"battleNet/middlewaree"
+	"battleNet/models"
+	"battleNet/openapi"
+	"battleNet/permissions"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/cors"
+)
+
+// New grąžina visų HTML ir /api/v1 maršrutų routerį
+func New(handler *handlers.Handler, sessionManager *scs.SessionManager, policy *permissions.Policy, cfg *config.Config) *chi.Mux {
+	r := chi.NewRouter()
+
+	can := func(permission string) func(http.Handler) http.Handler {
+		return middlewaree.RequirePermission(sessionManager, policy, permission)
+	}
+	canAPI := func(permission string) func(http.Handler) http.Handler {
+		return middlewaree.RequirePermissionAPI(sessionManager, handler, policy, permission)
+	}
+
+	// Middleware
+	r.Use(middleware.Logger)
+	r.Use(middleware.Recoverer)
+	r.Use(middleware.RequestID)
+	r.Use(middlewaree.RealIP(cfg.TrustedProxies))
+	r.Use(sessionManager.LoadAndSave)
+
+	// CORS middlewaree
+	r.Use(cors.Handler(cors.Options{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowCredentials: true,
+		MaxAge:           300,
+	}))
+
+	// Static files
+	r.Handle("/static/*", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
+
+	// HTML puslapiai. CSRF apsauga formoms ir HTMX užklausoms; static ir
+	// /api/v1/auth (token endpoint'ai slapukų nenaudoja) jos nereikia.
+	r.Group(func(r chi.Router) {
+		r.Use(middlewaree.CSRF(sessionManager))
+
+		// Public routes
+		r.Get("/", handler.HandleHome)
+		r.Get("/login", handler.HandleLoginPage)
+		r.Post("/login", handler.HandleLogin)
+		r.Get("/signup", handler.HandleSignupPage)
+		r.Post("/signup", handler.HandleSignup)
+		r.Get("/search", handler.HandleSearchMovies)
+		r.Get("/auth/{provider}/login", handler.HandleOAuthLogin)
+		r.Get("/auth/{provider}/callback", handler.HandleOAuthCallback)
+		r.Get("/verify-email", handler.HandleVerifyEmail)
+		r.Get("/forgot-password", handler.HandleForgotPasswordPage)
+		r.Post("/forgot-password", handler.HandleForgotPassword)
+		r.Get("/reset-password", handler.HandleResetPasswordPage)
+		r.Post("/reset-password", handler.HandleResetPassword)
+		r.Get("/login/2fa", handler.HandleTwoFactorLoginPage)
+		r.Post("/login/2fa", handler.HandleTwoFactorLogin)
+
+		// API dokumentacija (iš /api/v1/openapi.json)
+		r.Get("/api/docs", handler.HandleAPIDocs)
+
+		// Protected routes
+		r.Group(func(r chi.Router) {
+			r.Use(middlewaree.RequireAuth(sessionManager))
+
+			r.Get("/dashboard", handler.HandleDashboard)
+			r.Get("/profile", handler.HandleProfile)
+			r.Get("/logout", handler.HandleLogout)
+			r.Get("/movies", handler.HandleMovies)
+			r.Get("/movies/{id}", handler.HandleMovieDetail)
+			r.Get("/people/{id}", handler.HandlePersonPage)
+			r.Get("/watchlist", handler.HandleWatchlist)
+			r.Post("/watchlist/add", handler.HandleAddToWatchlist)
+			r.With(middlewaree.RequireVerifiedEmail(cfg.RequireVerifiedEmail)).Post("/reviews", handler.HandleCreateReview)
+			r.Post("/watchlist/remove", handler.HandleRemoveFromWatchlist)
+			r.Delete("/watchlist/{movieId}", handler.HandleHTMXRemoveFromWatchlist)
+			r.Get("/profile/edit", handler.HandleEditProfilePage)
+			r.Post("/profile/edit", handler.HandleUpdateProfile)
+			r.Get("/profile/change-password", handler.HandleChangePasswordPage)
+			r.Post("/profile/change-password", handler.HandleChangePassword)
+			r.Get("/profile/connections/{provider}/link", handler.HandleOAuthLink)
+			r.Post("/profile/connections/unlink", handler.HandleOAuthUnlink)
+			r.Post("/verify-email/resend", handler.HandleResendVerification)
+			r.Get("/profile/sessions", handler.HandleSessionsPage)
+			r.Post("/profile/sessions/revoke", handler.HandleRevokeSession)
+			r.Post("/profile/sessions/revoke-others", handler.HandleRevokeOtherSessions)
+			r.Get("/profile/tokens", handler.HandleTokensPage)
+			r.Post("/profile/tokens", handler.HandleCreateToken)
+			r.Post("/profile/tokens/revoke", handler.HandleRevokeToken)
+			r.Get("/profile/2fa", handler.HandleTwoFactorPage)
+			r.Post("/profile/2fa/enable", handler.HandleEnableTwoFactor)
+			r.Post("/profile/2fa/disable", handler.HandleDisableTwoFactor)
+			r.Post("/profile/2fa/recovery-codes", handler.HandleRegenerateRecoveryCodes)
+
+			// Admin routes
+			r.Group(func(r chi.Router) {
+				r.Use(can(permissions.MovieUpdate))
+				r.Use(middlewaree.RequireTwoFactor(sessionManager, cfg.RequireStaffTwoFactor))
+
+				r.Get("/admin/movies", handler.HandleAdminMovies)
+				r.With(can(permissions.MovieCreate)).Get("/admin/movies/create", handler.HandleCreateMoviePage)
+				r.With(can(permissions.MovieCreate)).Post("/admin/movies/create", handler.HandleCreateMovie)
+				r.Get("/admin/movies/edit", handler.HandleEditMoviePage)
+				r.Post("/admin/movies/update", handler.HandleUpdateMovie)
+				r.With(can(permissions.MovieDelete)).Post("/admin/movies/delete", handler.HandleDeleteMovie)
+			})
+
+			// Moderator routes (admin paveldi visus moderatoriaus leidimus)
+			r.Group(func(r chi.Router) {
+				r.Use(can(permissions.UserView))
+				r.Use(middlewaree.RequireTwoFactor(sessionManager, cfg.RequireStaffTwoFactor))
+
+				r.Get("/moderator/dashboard", handler.HandleModeratorDashboard)
+				r.Get("/moderator/users", handler.HandleModeratorUsers)
+				r.With(can(permissions.UserRoleUpdate)).Post("/moderator/users/update-role", handler.HandleModeratorUpdateRole)
+				r.With(can(permissions.UserDeactivate)).Post("/moderator/users/deactivate", handler.HandleModeratorDeactivateUser)
+				r.With(can(permissions.UserDeactivate)).Post("/moderator/users/reactivate", handler.HandleModeratorReactivateUser)
+				r.With(can(permissions.UserUnlock)).Post("/moderator/users/unlock", handler.HandleModeratorUnlockUser)
+			})
+
+			// Audito žurnalas
+			r.With(can(permissions.AuditView), middlewaree.RequireTwoFactor(sessionManager, cfg.RequireStaffTwoFactor)).
+				Get("/admin/audit", handler.HandleAdminAudit)
+
+			// TMDB importas
+			r.Group(func(r chi.Router) {
+				r.Use(can(permissions.MovieImport))
+				r.Use(middlewaree.RequireTwoFactor(sessionManager, cfg.RequireStaffTwoFactor))
+
+				r.Post("/admin/movies/import", handler.HandleImportMovie)
+				r.Post("/moderator/movies/import", handler.HandleImportMovie)
+			})
+		})
+	})
+
+	// API routes (REST API)
+	r.Route("/api/v1", func(r chi.Router) {
+		r.NotFound(response.NotFound)
+		r.MethodNotAllowed(response.MethodNotAllowed)
+
+		// Public API endpoints
+		r.Get("/openapi.json", openapi.Handler)
+		r.Get("/movies", handler.HandleAPIMovies)
+		r.Get("/movies/{id}", handler.HandleAPIMovieDetail)
+		r.Get("/people/{id}", handler.HandleAPIPerson)
+		r.Get("/reviews", handler.HandleAPIReviews)
+		r.Get("/tmdb/search", handler.HandleAPISearchMovies)
+		r.Post("/auth/token", handler.HandleAPIToken)
+		r.Post("/auth/refresh", handler.HandleAPIRefreshToken)
+		r.Post("/auth/revoke", handler.HandleAPIRevokeToken)
+
+		// Endpoint'ai, pasiekiami ir su sesijos slapuku - jiems reikia CSRF token'o
+		// (užklausoms su Bearer token'u - ne)
+		r.Group(func(r chi.Router) {
+			r.Use(middlewaree.CSRFAPI(sessionManager))
+
+			// Protected API endpoints
+			r.Group(func(r chi.Router) {
+				r.Use(middlewaree.RequireAuthAPI(sessionManager, handler))
+
+				r.With(
+					middlewaree.RequireScope(models.ScopeReviewsWrite),
+					middlewaree.RequireVerifiedEmailAPI(cfg.RequireVerifiedEmail),
+				).Post("/reviews", handler.HandleAPICreateReview)
+				r.With(middlewaree.RequireScope(models.ScopeWatchlistRead)).Get("/watchlist", handler.HandleAPIWatchlist)
+
+				r.Group(func(r chi.Router) {
+					r.Use(middlewaree.RequireScope(models.ScopeWatchlistWrite))
+
+					r.Post("/watchlist", handler.HandleAPIAddToWatchlist)
+					r.Delete("/watchlist/{movieId}", handler.HandleAPIRemoveFromWatchlist)
+				})
+			})
+
+			//Moderator API endpoints
+			r.Group(func(r chi.Router) {
+				r.Use(canAPI(permissions.UserView))
+				r.Use(middlewaree.RequireTwoFactorAPI(cfg.RequireStaffTwoFactor))
+				r.Use(middlewaree.RequireScope(models.ScopeUsersModerate))
+
+				r.Get("/moderator/users", handler.HandleAPIModeratorUsers)
+				r.With(canAPI(permissions.UserRoleUpdate)).Put("/moderator/users/{id}/role", handler.HandleAPIModeratorUpdateRole)
+				r.With(canAPI(permissions.UserDeactivate)).Delete("/moderator/users/{id}", handler.HandleAPIModeratorDeactivateUser)
+			})
+
+			// Admin API endpoints
+			r.Group(func(r chi.Router) {
+				r.Use(canAPI(permissions.MovieUpdate))
+				r.Use(middlewaree.RequireTwoFactorAPI(cfg.RequireStaffTwoFactor))
+				r.Use(middlewaree.RequireScope(models.ScopeMoviesAdmin))
+
+				r.With(canAPI(permissions.MovieCreate)).Post("/movies", handler.HandleAPICreateMovie)
+				r.Put("/movies/{id}", handler.HandleAPIUpdateMovie)
+				r.Patch("/movies/{id}", handler.HandleAPIPatchMovie)
+				r.With(canAPI(permissions.MovieDelete)).Delete("/movies/{id}", handler.HandleAPIDeleteMovie)
+			})
+
+			// Audit API
+			r.Group(func(r chi.Router) {
+				r.Use(canAPI(permissions.AuditView))
+				r.Use(middlewaree.RequireTwoFactorAPI(cfg.RequireStaffTwoFactor))
+				r.Use(middlewaree.RequireScope(models.ScopeAuditRead))
+
+				r.Get("/admin/audit", handler.HandleAPIAdminAudit)
+			})
+		})
+	})
+
+	return r
+}
@@ -0,0 +1,197 @@
+// Package validation checks form and JSON input field by field. A Validator
+// collects at most one error per field (the first one wins), so the same
+// rules can back both the Templ forms, which show each message next to its
+// field, and the API, which returns them as the "errors" member of a 422
+// problem response.
+//
+//	v := validation.New()
+//	v.Required("title", title)
+//	v.MaxLength("title", title, 500)
+//	runtime := v.Int("runtime", form.Get("runtime"))
+//	if !v.Valid() { ... v.Errors ... }
+package validation
+
+import (
+	"net/mail"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Slaptažodžio ilgis baitais. bcrypt naudoja tik pirmus 72 baitus.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// DateLayout - datos formatas formose ir API (YYYY-MM-DD)
+const DateLayout = "2006-01-02"
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Errors - klaidos pagal lauko pavadinimą
+type Errors map[string]string
+
+// Get grąžina lauko klaidą arba ""
+func (e Errors) Get(field string) string {
+	return e[field]
+}
+
+// Has - ar laukas turi klaidą
+func (e Errors) Has(field string) bool {
+	_, ok := e[field]
+	return ok
+}
+
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field, msg := range e {
+		fields = append(fields, field+" "+msg)
+	}
+	slices.Sort(fields)
+	return strings.Join(fields, "; ")
+}
+
+// Validator kaupia lauko klaidas
+type Validator struct {
+	Errors Errors
+}
+
+func New() *Validator {
+	return &Validator{Errors: Errors{}}
+}
+
+// Valid - ar nėra nė vienos klaidos
+func (v *Validator) Valid() bool {
+	return len(v.Errors) == 0
+}
+
+// AddError įrašo klaidą, jei laukas jos dar neturi
+func (v *Validator) AddError(field, message string) {
+	if !v.Errors.Has(field) {
+		v.Errors[field] = message
+	}
+}
+
+// Check įrašo klaidą, jei ok == false
+func (v *Validator) Check(ok bool, field, message string) {
+	if !ok {
+		v.AddError(field, message)
+	}
+}
+
+// Required - reikšmė netuščia (tarpai nesiskaito)
+func (v *Validator) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+// MinLength - bent n simbolių
+func (v *Validator) MinLength(field, value string, n int) {
+	v.Check(utf8.RuneCountInString(value) >= n, field, "must be at least "+strconv.Itoa(n)+" characters")
+}
+
+// MaxLength - ne daugiau n simbolių (atitinka VARCHAR(n))
+func (v *Validator) MaxLength(field, value string, n int) {
+	v.Check(utf8.RuneCountInString(value) <= n, field, "must be at most "+strconv.Itoa(n)+" characters")
+}
+
+// Email - vienas adresas be vardo ("a@b.lt", ne "A <a@b.lt>")
+func (v *Validator) Email(field, value string) {
+	addr, err := mail.ParseAddress(value)
+	ok := err == nil && addr.Address == value
+	if ok {
+		// net/mail priima "a@localhost" - reikalaujame domeno su tašku
+		domain := value[strings.LastIndex(value, "@")+1:]
+		ok = strings.Contains(domain, ".")
+	}
+	v.Check(ok, field, "must be a valid email address")
+}
+
+// Username - raidės, skaičiai, '.', '_' ir '-'
+func (v *Validator) Username(field, value string) {
+	v.Check(usernamePattern.MatchString(value), field, "may only contain letters, digits, '.', '_' and '-'")
+}
+
+// Password - ilgis tarp MinPasswordLength ir MaxPasswordLength baitų
+func (v *Validator) Password(field, value string) {
+	v.Check(len(value) >= MinPasswordLength, field, "must be at least "+strconv.Itoa(MinPasswordLength)+" characters")
+	v.Check(len(value) <= MaxPasswordLength, field, "must be at most "+strconv.Itoa(MaxPasswordLength)+" bytes")
+}
+
+// OneOf - reikšmė yra tarp leistinų
+func (v *Validator) OneOf(field, value string, allowed []string) {
+	v.Check(slices.Contains(allowed, value), field, "must be one of: "+strings.Join(allowed, ", "))
+}
+
+// Between - min <= value <= max
+func (v *Validator) Between(field string, value, min, max float64) {
+	v.Check(value >= min && value <= max, field, "must be between "+formatNumber(min)+" and "+formatNumber(max))
+}
+
+// NotNegative - value >= 0
+func (v *Validator) NotNegative(field string, value float64) {
+	v.Check(value >= 0, field, "must not be negative")
+}
+
+// Date nuskaito YYYY-MM-DD. Tuščia reikšmė - nil be klaidos.
+func (v *Validator) Date(field, value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		v.AddError(field, "must be a date in YYYY-MM-DD format")
+		return nil
+	}
+	return &t
+}
+
+// Int nuskaito sveiką skaičių. Tuščia reikšmė - nil be klaidos.
+func (v *Validator) Int(field, value string) *int {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		v.AddError(field, "must be a whole number")
+		return nil
+	}
+	return &n
+}
+
+// Float nuskaito skaičių. Tuščia reikšmė - nil be klaidos.
+func (v *Validator) Float(field, value string) *float64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		v.AddError(field, "must be a number")
+		return nil
+	}
+	return &f
+}
+
+// Unique pažymi lauką, jei exists grąžina true. Jei laukas jau turi klaidą,
+// exists nekviečiamas (nėra prasmės tikrinti DB neteisingai reikšmei).
+// Grąžina tik exists klaidą.
+func (v *Validator) Unique(field, message string, exists func() (bool, error)) error {
+	if v.Errors.Has(field) {
+		return nil
+	}
+	taken, err := exists()
+	if err != nil {
+		return err
+	}
+	v.Check(!taken, field, message)
+	return nil
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
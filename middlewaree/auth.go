@@ -3,6 +3,10 @@ package middlewaree
 import (
 	"context"
 	"net/http"
+	"slices"
+	"strings"
+
+	"battleNet/internal/response"
 
 	"github.com/alexedwards/scs/v2"
 )
@@ -10,9 +14,12 @@ import (
 type contextKey string
 
 const (
-	UserIDKey contextKey = "userID"
-	EmailKey  contextKey = "email"
-	RoleKey   contextKey = "role"
+	UserIDKey        contextKey = "userID"
+	EmailKey         contextKey = "email"
+	RoleKey          contextKey = "role"
+	EmailVerifiedKey contextKey = "emailVerified"
+	ScopesKey        contextKey = "scopes"
+	TwoFactorKey     contextKey = "twoFactor"
 )
 
 // - Middleware for web routes
@@ -29,47 +36,140 @@ func RequireAuth(sm *scs.SessionManager) func(http.Handler) http.Handler {
 			ctx := context.WithValue(r.Context(), UserIDKey, sm.GetString(r.Context(), "userID"))
 			ctx = context.WithValue(ctx, EmailKey, sm.GetString(r.Context(), "email"))
 			ctx = context.WithValue(ctx, RoleKey, sm.GetString(r.Context(), "role"))
+			ctx = context.WithValue(ctx, EmailVerifiedKey, sm.GetBool(r.Context(), "email_verified"))
+			ctx = context.WithValue(ctx, TwoFactorKey, sm.GetBool(r.Context(), "two_factor"))
 
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
-// RequireAuthAPI - Middleware for API routes
-func RequireAuthAPI(sm *scs.SessionManager) func(http.Handler) http.Handler {
+// Principal - autentifikuotas API vartotojas (iš sesijos slapuko arba Bearer token'o)
+type Principal struct {
+	UserID        string
+	Email         string
+	Role          string
+	EmailVerified bool
+	TwoFactor     bool     // prisijungta su antruoju faktoriumi (TOTP)
+	Scopes        []string // nil - be apribojimų (sesija, JWT); personal access token'ams - suteikti scope'ai
+}
+
+// BearerAuthenticator validates the token from an "Authorization: Bearer" header
+type BearerAuthenticator interface {
+	AuthenticateBearer(ctx context.Context, token string) (*Principal, error)
+}
+
+// RequireAuthAPI - Middleware for API routes. Accepts a Bearer token or the session cookie.
+func RequireAuthAPI(sm *scs.SessionManager, authenticator BearerAuthenticator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			authenticated := sm.GetBool(r.Context(), "authenticated")
-			if !authenticated {
-				http.Error(w, `{"error": "Unauthorized"}`, http.StatusUnauthorized)
+			principal := authenticateAPI(sm, authenticator, r)
+			if principal == nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				response.Error(w, r, http.StatusUnauthorized, response.CodeUnauthorized, "Unauthorized")
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), UserIDKey, sm.GetString(r.Context(), "userID"))
-			ctx = context.WithValue(ctx, EmailKey, sm.GetString(r.Context(), "email"))
-			ctx = context.WithValue(ctx, RoleKey, sm.GetString(r.Context(), "role"))
+			next.ServeHTTP(w, r.WithContext(withPrincipal(r.Context(), principal)))
+		})
+	}
+}
 
-			next.ServeHTTP(w, r.WithContext(ctx))
+// authenticateAPI grąžina vartotoją iš Bearer token'o arba sesijos. Jei
+// Authorization antraštė yra, slapukas nebetikrinamas.
+func authenticateAPI(sm *scs.SessionManager, authenticator BearerAuthenticator, r *http.Request) *Principal {
+	if header := r.Header.Get("Authorization"); header != "" {
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || authenticator == nil {
+			return nil
+		}
+
+		principal, err := authenticator.AuthenticateBearer(r.Context(), strings.TrimSpace(token))
+		if err != nil {
+			return nil
+		}
+		return principal
+	}
+
+	if !sm.GetBool(r.Context(), "authenticated") {
+		return nil
+	}
+
+	return &Principal{
+		UserID:        sm.GetString(r.Context(), "userID"),
+		Email:         sm.GetString(r.Context(), "email"),
+		Role:          sm.GetString(r.Context(), "role"),
+		EmailVerified: sm.GetBool(r.Context(), "email_verified"),
+		TwoFactor:     sm.GetBool(r.Context(), "two_factor"),
+	}
+}
+
+func withPrincipal(ctx context.Context, principal *Principal) context.Context {
+	ctx = context.WithValue(ctx, UserIDKey, principal.UserID)
+	ctx = context.WithValue(ctx, EmailKey, principal.Email)
+	ctx = context.WithValue(ctx, RoleKey, principal.Role)
+	ctx = context.WithValue(ctx, EmailVerifiedKey, principal.EmailVerified)
+	ctx = context.WithValue(ctx, TwoFactorKey, principal.TwoFactor)
+	if principal.Scopes != nil {
+		ctx = context.WithValue(ctx, ScopesKey, principal.Scopes)
+	}
+	return ctx
+}
+
+// RequireScope blocks personal access tokens that weren't granted scope.
+// Session and JWT requests carry no scope restriction. Must run after
+// RequireAuthAPI or RequirePermissionAPI.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if scopes, restricted := r.Context().Value(ScopesKey).([]string); restricted && !slices.Contains(scopes, scope) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+				response.Error(w, r, http.StatusForbidden, response.CodeInsufficientScope, "Token is missing the "+scope+" scope")
+				return
+			}
+
+			next.ServeHTTP(w, r)
 		})
 	}
 }
 
-// Middleware for specific roles (web)
-func RequireRole(sm *scs.SessionManager, allowedRoles ...string) func(http.Handler) http.Handler {
+// PermissionChecker answers whether a role holds a named permission
+type PermissionChecker interface {
+	Can(role, permission string) bool
+}
+
+// RequirePermission blocks users whose role lacks permission (web)
+func RequirePermission(sm *scs.SessionManager, checker PermissionChecker, permission string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			userRole := sm.GetString(r.Context(), "role")
-			hasAccess := false
+			if !checker.Can(sm.GetString(r.Context(), "role"), permission) {
+				http.Error(w, "Access Denied - Insufficient privileges", http.StatusForbidden)
+				return
+			}
 
-			for _, role := range allowedRoles {
-				if userRole == role {
-					hasAccess = true
-					break
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePermissionAPI blocks users whose role lacks permission (API).
+// Authenticates the request itself when RequireAuthAPI hasn't already run.
+func RequirePermissionAPI(sm *scs.SessionManager, authenticator BearerAuthenticator, checker PermissionChecker, permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.Context().Value(UserIDKey).(string); !ok {
+				principal := authenticateAPI(sm, authenticator, r)
+				if principal == nil {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+					response.Error(w, r, http.StatusUnauthorized, response.CodeUnauthorized, "Unauthorized")
+					return
 				}
+				r = r.WithContext(withPrincipal(r.Context(), principal))
 			}
 
-			if !hasAccess {
-				http.Error(w, "Access Denied - Insufficient privileges", http.StatusForbidden)
+			userRole, _ := r.Context().Value(RoleKey).(string)
+			if !checker.Can(userRole, permission) {
+				response.Error(w, r, http.StatusForbidden, response.CodeForbidden, "Access Denied - Insufficient privileges")
 				return
 			}
 
@@ -78,22 +178,59 @@ func RequireRole(sm *scs.SessionManager, allowedRoles ...string) func(http.Handl
 	}
 }
 
-// Middleware for specific roles (API)
-func RequireRoleAPI(sm *scs.SessionManager, allowedRoles ...string) func(http.Handler) http.Handler {
+// RequireVerifiedEmail blocks unverified accounts when enforce is true (web).
+// Must run after RequireAuth.
+func RequireVerifiedEmail(enforce bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			userRole := sm.GetString(r.Context(), "role")
-			hasAccess := false
+			if verified, _ := r.Context().Value(EmailVerifiedKey).(bool); enforce && !verified {
+				http.Error(w, "Please confirm your email address first - you can resend the link from your profile", http.StatusForbidden)
+				return
+			}
 
-			for _, role := range allowedRoles {
-				if userRole == role {
-					hasAccess = true
-					break
-				}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireVerifiedEmailAPI blocks unverified accounts when enforce is true (API).
+// Must run after RequireAuthAPI.
+func RequireVerifiedEmailAPI(enforce bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if verified, _ := r.Context().Value(EmailVerifiedKey).(bool); enforce && !verified {
+				response.Error(w, r, http.StatusForbidden, response.CodeEmailNotVerified, "Email address not verified")
+				return
 			}
 
-			if !hasAccess {
-				http.Error(w, `{"error": "Access Denied - Insufficient privileges"}`, http.StatusForbidden)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireTwoFactor sends users who didn't sign in with a second factor to the
+// 2FA setup page when enforce is true (web). Must run after RequireAuth.
+func RequireTwoFactor(sm *scs.SessionManager, enforce bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if twoFactor, _ := r.Context().Value(TwoFactorKey).(bool); enforce && !twoFactor {
+				sm.Put(r.Context(), "flash_error", "Two-factor authentication is required for your role. Set it up to continue.")
+				http.Redirect(w, r, "/profile/2fa", http.StatusSeeOther)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireTwoFactorAPI rejects requests not authenticated with a second factor
+// when enforce is true (API). Must run after RequireAuthAPI or RequirePermissionAPI.
+func RequireTwoFactorAPI(enforce bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if twoFactor, _ := r.Context().Value(TwoFactorKey).(bool); enforce && !twoFactor {
+				response.Error(w, r, http.StatusForbidden, response.CodeTwoFactorRequired, "Two-factor authentication required")
 				return
 			}
 
@@ -0,0 +1,101 @@
+package middlewaree
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"battleNet/internal/response"
+
+	"github.com/alexedwards/scs/v2"
+)
+
+const (
+	csrfSessionManagerKey contextKey = "csrfSessionManager"
+
+	// CSRFSessionKey - sesijos raktas, kuriame saugomas synchronizer token'as
+	CSRFSessionKey = "csrf_token"
+	CSRFFormField  = "csrf_token"
+	CSRFHeader     = "X-CSRF-Token"
+)
+
+// CSRF rejects state-changing requests that don't echo the session's
+// synchronizer token back in the csrf_token form field or the X-CSRF-Token
+// header. It is meant for cookie-authenticated HTML routes, so an
+// Authorization header doesn't exempt a request. A missing token is issued
+// before the handler runs: scs commits the session on the first write to the
+// response, so a token created while a page is being rendered could be lost.
+// Must run after sm.LoadAndSave.
+func CSRF(sm *scs.SessionManager) func(http.Handler) http.Handler {
+	return csrf(sm, true, false)
+}
+
+// CSRFAPI is CSRF for API routes that also accept Bearer tokens (RequireAuthAPI).
+// Requests with an Authorization header are authenticated by the token, not the
+// session cookie, so they skip the check. It never issues a token: API clients
+// without a session must not get one, and cookie clients receive theirs from the
+// HTML pages.
+func CSRFAPI(sm *scs.SessionManager) func(http.Handler) http.Handler {
+	return csrf(sm, false, true)
+}
+
+func csrf(sm *scs.SessionManager, issue, allowBearer bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = r.WithContext(context.WithValue(r.Context(), csrfSessionManagerKey, sm))
+			token := sm.GetString(r.Context(), CSRFSessionKey)
+
+			if csrfSafeMethod(r.Method) || (allowBearer && r.Header.Get("Authorization") != "") {
+				if issue && token == "" {
+					sm.Put(r.Context(), CSRFSessionKey, rand.Text())
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			sent := r.Header.Get(CSRFHeader)
+			if sent == "" {
+				sent = r.FormValue(CSRFFormField)
+			}
+
+			if token == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+				if strings.HasPrefix(r.URL.Path, "/api/") {
+					response.Error(w, r, http.StatusForbidden, response.CodeCSRF, "Invalid or missing CSRF token")
+					return
+				}
+				http.Error(w, "Invalid or missing CSRF token - reload the page and try again", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// CSRFToken grąžina sesijos CSRF token'ą (tuščią, jei CSRF middleware nepaleistas).
+// Sesijos nekeičia, todėl saugu kviesti ir jau rašant atsakymą.
+func CSRFToken(ctx context.Context) string {
+	sm, ok := ctx.Value(csrfSessionManagerKey).(*scs.SessionManager)
+	if !ok {
+		return ""
+	}
+	return sm.GetString(ctx, CSRFSessionKey)
+}
+
+// RenewCSRFToken pakeičia sesijos CSRF token'ą nauju (pvz. po prisijungimo).
+// Kaip ir kitus sesijos pakeitimus, kviesti reikia prieš rašant atsakymą.
+func RenewCSRFToken(ctx context.Context) {
+	if sm, ok := ctx.Value(csrfSessionManagerKey).(*scs.SessionManager); ok {
+		sm.Put(ctx, CSRFSessionKey, rand.Text())
+	}
+}
+
+func csrfSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
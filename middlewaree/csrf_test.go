@@ -0,0 +1,91 @@
+package middlewaree
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/alexedwards/scs/v2"
+)
+
+// csrfServer - LoadAndSave + csrf; GET atsakymas didesnis už templ buferį, o
+// token'as įrašomas tik jo gale, kaip formoje puslapio apačioje
+func csrfServer(t *testing.T, middleware func(*scs.SessionManager) func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+	sm := scs.New()
+	server := httptest.NewServer(sm.LoadAndSave(middleware(sm)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			io.WriteString(w, strings.Repeat(" ", 8192))
+			io.WriteString(w, CSRFToken(r.Context()))
+		}
+	}))))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCSRFIssuesTokenBeforeHandler(t *testing.T) {
+	server := csrfServer(t, CSRF)
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	token := strings.TrimSpace(string(body))
+	if token == "" {
+		t.Fatal("GET did not render a CSRF token")
+	}
+
+	post := func(token string) int {
+		req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(url.Values{CSRFFormField: {token}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, cookie := range resp.Cookies() {
+			req.AddCookie(cookie)
+		}
+		postResp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		postResp.Body.Close()
+		return postResp.StatusCode
+	}
+
+	if status := post(token); status != http.StatusOK {
+		t.Errorf("POST with the rendered token: status %d, want %d", status, http.StatusOK)
+	}
+	if status := post("wrong"); status != http.StatusForbidden {
+		t.Errorf("POST with a wrong token: status %d, want %d", status, http.StatusForbidden)
+	}
+}
+
+// Authorization antraštė CSRF patikrą praleidžia tik Bearer maršrutuose
+func TestCSRFAuthorizationHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		middleware func(*scs.SessionManager) func(http.Handler) http.Handler
+		want       int
+	}{
+		{"HTML routes", CSRF, http.StatusForbidden},
+		{"Bearer API routes", CSRFAPI, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := csrfServer(t, tt.middleware)
+			req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/v1/reviews", nil)
+			req.Header.Set("Authorization", "Bearer anything")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE UNIQUE INDEX idx_oauth_provider_identity ON oauth(provider, provider_id);
+CREATE INDEX idx_oauth_user_id ON oauth(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_oauth_user_id;
+DROP INDEX IF EXISTS idx_oauth_provider_identity;
+-- +goose StatementEnd
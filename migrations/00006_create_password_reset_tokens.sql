@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE password_reset_token (
+                                      token_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                      user_id UUID NOT NULL REFERENCES "user"(user_id) ON DELETE CASCADE,
+                                      token_hash CHAR(64) UNIQUE NOT NULL, -- SHA-256 hex, pats token'as nesaugomas
+                                      expires_at TIMESTAMPTZ NOT NULL,
+                                      used_at TIMESTAMPTZ,
+                                      created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_password_reset_token_user_id ON password_reset_token(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS password_reset_token;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- user_session dabar naudojama kaip SCS session store: sesija gali egzistuoti
+-- ir prieš prisijungimą (pvz. OAuth state), todėl user_id tampa neprivalomas
+ALTER TABLE user_session ADD COLUMN data BYTEA NOT NULL DEFAULT ''::bytea;
+ALTER TABLE user_session ALTER COLUMN user_id DROP NOT NULL;
+
+CREATE INDEX idx_user_session_user_id ON user_session(user_id);
+CREATE INDEX idx_user_session_expires_at ON user_session(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_user_session_expires_at;
+DROP INDEX IF EXISTS idx_user_session_user_id;
+DELETE FROM user_session WHERE user_id IS NULL;
+ALTER TABLE user_session ALTER COLUMN user_id SET NOT NULL;
+ALTER TABLE user_session DROP COLUMN IF EXISTS data;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- API klientų (skriptų, mobilių programėlių) sesijos. Refresh token'as
+-- saugomas tik kaip SHA-256 hash ir keičiamas kiekvieno atnaujinimo metu.
+CREATE TABLE api_session (
+                             session_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                             user_id UUID NOT NULL REFERENCES "user"(user_id) ON DELETE CASCADE,
+                             refresh_token_hash CHAR(64) UNIQUE NOT NULL,
+                             device_info TEXT,
+                             ip_address INET,
+                             expires_at TIMESTAMPTZ NOT NULL,
+                             revoked_at TIMESTAMPTZ,
+                             created_at TIMESTAMPTZ DEFAULT NOW(),
+                             last_used_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_session_user_id ON api_session(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_session;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE personal_access_token (
+                                       token_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                       user_id UUID NOT NULL REFERENCES "user"(user_id) ON DELETE CASCADE,
+                                       name VARCHAR(100) NOT NULL,
+                                       token_hash CHAR(64) UNIQUE NOT NULL, -- SHA-256 hex, pats token'as rodomas tik vieną kartą
+                                       token_prefix VARCHAR(16) NOT NULL,   -- atpažinimui sąraše, pvz. bnpat_Ab12Cd
+                                       scopes TEXT[] NOT NULL DEFAULT '{}',
+                                       expires_at TIMESTAMPTZ,              -- NULL = negalioja niekada
+                                       last_used_at TIMESTAMPTZ,
+                                       created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_personal_access_token_user_id ON personal_access_token(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS personal_access_token;
+-- +goose StatementEnd
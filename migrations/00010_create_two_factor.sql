@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- TOTP 2FA. Eilutė su enabled_at IS NULL reiškia nebaigtą registraciją.
+CREATE TABLE user_totp (
+                           user_id UUID PRIMARY KEY REFERENCES "user"(user_id) ON DELETE CASCADE,
+                           secret VARCHAR(64) NOT NULL,
+                           enabled_at TIMESTAMPTZ,
+                           last_used_step BIGINT, -- paskutinio panaudoto kodo žingsnis, kad kodas nebūtų panaudotas du kartus
+                           created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE two_factor_recovery_code (
+                                          code_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                          user_id UUID NOT NULL REFERENCES "user"(user_id) ON DELETE CASCADE,
+                                          code_hash CHAR(64) NOT NULL,
+                                          used_at TIMESTAMPTZ,
+                                          created_at TIMESTAMPTZ DEFAULT NOW(),
+                                          UNIQUE (user_id, code_hash)
+);
+
+-- Ar API sesija sukurta su antruoju faktoriumi (admin/moderator API reikalauja, kai 2FA privalomas)
+ALTER TABLE api_session ADD COLUMN two_factor BOOLEAN NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE api_session DROP COLUMN IF EXISTS two_factor;
+DROP TABLE IF EXISTS two_factor_recovery_code;
+DROP TABLE IF EXISTS user_totp;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Kiekvienas prisijungimo bandymas (auditui)
+CREATE TABLE login_attempt (
+                               attempt_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                               email VARCHAR(255) NOT NULL,
+                               user_id UUID REFERENCES "user"(user_id) ON DELETE SET NULL,
+                               ip_address INET,
+                               user_agent TEXT,
+                               success BOOLEAN NOT NULL,
+                               reason VARCHAR(50), -- invalid_password, unknown_email, locked, invalid_2fa, ...
+                               attempted_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_login_attempt_email ON login_attempt(email, attempted_at);
+CREATE INDEX idx_login_attempt_ip_address ON login_attempt(ip_address, attempted_at);
+
+-- Nesėkmių skaitliukai ir laikinos blokuotės (per paskyrą ir per IP)
+CREATE TABLE login_lockout (
+                               scope VARCHAR(10) NOT NULL CHECK (scope IN ('account', 'ip')),
+                               subject VARCHAR(255) NOT NULL, -- email (mažosiomis) arba IP adresas
+                               failures INT NOT NULL DEFAULT 0,
+                               last_failure_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                               locked_until TIMESTAMPTZ,
+                               PRIMARY KEY (scope, subject)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS login_lockout;
+DROP TABLE IF EXISTS login_attempt;
+-- +goose StatementEnd
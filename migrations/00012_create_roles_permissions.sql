@@ -0,0 +1,61 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Rolių hierarchija: rolė turi savo leidimus ir visus parent_role leidimus
+CREATE TABLE role (
+                      name VARCHAR(50) PRIMARY KEY,
+                      parent_role VARCHAR(50) REFERENCES role(name),
+                      description TEXT,
+                      created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE permission (
+                            name VARCHAR(100) PRIMARY KEY,
+                            description TEXT
+);
+
+CREATE TABLE role_permission (
+                                 role_name VARCHAR(50) NOT NULL REFERENCES role(name) ON DELETE CASCADE,
+                                 permission_name VARCHAR(100) NOT NULL REFERENCES permission(name) ON DELETE CASCADE,
+                                 PRIMARY KEY (role_name, permission_name)
+);
+
+INSERT INTO role (name, parent_role, description) VALUES
+    ('user', NULL, 'Regular member'),
+    ('moderator', 'user', 'Manages users and reviews'),
+    ('admin', 'moderator', 'Full access');
+
+INSERT INTO permission (name, description) VALUES
+    ('movie.create', 'Create movies'),
+    ('movie.update', 'Edit movies'),
+    ('movie.delete', 'Delete movies'),
+    ('movie.import', 'Import movies from TMDB'),
+    ('user.view', 'List users'),
+    ('user.role.update', 'Change user roles'),
+    ('user.deactivate', 'Deactivate users'),
+    ('user.unlock', 'Clear login lockouts'),
+    ('review.moderate', 'Moderate reviews');
+
+INSERT INTO role_permission (role_name, permission_name) VALUES
+    ('moderator', 'movie.import'),
+    ('moderator', 'user.view'),
+    ('moderator', 'user.role.update'),
+    ('moderator', 'user.deactivate'),
+    ('moderator', 'user.unlock'),
+    ('moderator', 'review.moderate'),
+    ('admin', 'movie.create'),
+    ('admin', 'movie.update'),
+    ('admin', 'movie.delete');
+
+-- Vartotojo rolė turi egzistuoti role lentelėje vietoj fiksuoto CHECK sąrašo
+ALTER TABLE "user" DROP CONSTRAINT IF EXISTS user_role_check;
+ALTER TABLE "user" ADD CONSTRAINT user_role_fkey FOREIGN KEY (role) REFERENCES role(name) ON UPDATE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "user" DROP CONSTRAINT IF EXISTS user_role_fkey;
+ALTER TABLE "user" ADD CONSTRAINT user_role_check CHECK (role IN ('user', 'admin', 'moderator'));
+DROP TABLE IF EXISTS role_permission;
+DROP TABLE IF EXISTS permission;
+DROP TABLE IF EXISTS role;
+-- +goose StatementEnd
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Privilegijuotų veiksmų auditas. Lentelė tik papildoma: UPDATE, DELETE ir
+-- TRUNCATE blokuojami trigeriais. actor_id sąmoningai be FK, kad vartotojo
+-- ištrynimas nekeistų audito įrašų.
+CREATE TABLE audit_event (
+                             audit_event_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                             actor_id UUID,
+                             actor_email VARCHAR(255) NOT NULL,
+                             action VARCHAR(100) NOT NULL,
+                             target_type VARCHAR(50) NOT NULL,
+                             target_id TEXT,
+                             changes JSONB NOT NULL DEFAULT '{}', -- {"laukas": {"before": ..., "after": ...}}
+                             ip_address INET,
+                             request_id TEXT,
+                             created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_event_created_at ON audit_event(created_at DESC);
+CREATE INDEX idx_audit_event_actor ON audit_event(actor_id, created_at DESC);
+CREATE INDEX idx_audit_event_action ON audit_event(action, created_at DESC);
+
+CREATE FUNCTION audit_event_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_event is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_event_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_event
+    FOR EACH ROW EXECUTE FUNCTION audit_event_append_only();
+
+CREATE TRIGGER audit_event_no_truncate
+    BEFORE TRUNCATE ON audit_event
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_event_append_only();
+
+INSERT INTO permission (name, description) VALUES ('audit.view', 'View the audit log');
+INSERT INTO role_permission (role_name, permission_name) VALUES ('admin', 'audit.view');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permission WHERE name = 'audit.view';
+DROP TABLE IF EXISTS audit_event;
+DROP FUNCTION IF EXISTS audit_event_append_only();
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Paskyrų suspendavimai. Aktyvi suspensija - lifted_at IS NULL; expires_at IS NULL reiškia neterminuotą.
+-- "user".is_active lieka suspensijos požymiu, kad esamos užklausos veiktų kaip anksčiau.
+CREATE TABLE user_suspension (
+                                 suspension_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                                 user_id UUID NOT NULL REFERENCES "user"(user_id) ON DELETE CASCADE,
+                                 reason TEXT NOT NULL,
+                                 suspended_by UUID REFERENCES "user"(user_id) ON DELETE SET NULL,
+                                 suspended_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                 expires_at TIMESTAMPTZ,
+                                 lifted_at TIMESTAMPTZ,
+                                 lifted_by UUID REFERENCES "user"(user_id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX idx_user_suspension_active ON user_suspension(user_id) WHERE lifted_at IS NULL;
+CREATE INDEX idx_user_suspension_expires_at ON user_suspension(expires_at) WHERE lifted_at IS NULL;
+
+-- Anksčiau deaktyvuoti vartotojai tampa neterminuotai suspenduotais
+INSERT INTO user_suspension (user_id, reason, suspended_at)
+SELECT user_id, 'Deactivated', updated_at
+FROM "user"
+WHERE is_active = false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_suspension;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Filmas gali turėti tą patį žanrą tik kartą
+DELETE FROM movie_genre a
+USING movie_genre b
+WHERE a.movie_id = b.movie_id
+  AND a.genre_id = b.genre_id
+  AND a.movie_genre_id > b.movie_genre_id;
+
+CREATE UNIQUE INDEX idx_movie_genre_movie_genre ON movie_genre(movie_id, genre_id);
+-- Filtravimui pagal žanrą (/movies?genre=Action)
+CREATE INDEX idx_movie_genre_genre_id ON movie_genre(genre_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_movie_genre_genre_id;
+DROP INDEX IF EXISTS idx_movie_genre_movie_genre;
+-- +goose StatementEnd
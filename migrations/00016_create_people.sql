@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Aktoriai ir kūrybinė komanda (importuojama iš TMDB credits)
+CREATE TABLE person (
+                        person_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                        tmdb_id INTEGER UNIQUE,
+                        name VARCHAR(255) NOT NULL,
+                        profile_path TEXT,
+                        created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- billing_order - TMDB "order" (0 - pagrindinis vaidmuo). Tas pats aktorius gali turėti kelis vaidmenis.
+CREATE TABLE movie_cast (
+                            movie_cast_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                            movie_id UUID NOT NULL REFERENCES movie(movie_id) ON DELETE CASCADE,
+                            person_id UUID NOT NULL REFERENCES person(person_id) ON DELETE CASCADE,
+                            character_name VARCHAR(500),
+                            billing_order INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE movie_crew (
+                            movie_crew_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+                            movie_id UUID NOT NULL REFERENCES movie(movie_id) ON DELETE CASCADE,
+                            person_id UUID NOT NULL REFERENCES person(person_id) ON DELETE CASCADE,
+                            department VARCHAR(100) NOT NULL,
+                            job VARCHAR(100) NOT NULL,
+                            UNIQUE (movie_id, person_id, job)
+);
+
+CREATE INDEX idx_movie_cast_movie_id ON movie_cast(movie_id, billing_order);
+CREATE INDEX idx_movie_cast_person_id ON movie_cast(person_id);
+CREATE INDEX idx_movie_crew_person_id ON movie_crew(person_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS movie_crew;
+DROP TABLE IF EXISTS movie_cast;
+DROP TABLE IF EXISTS person;
+-- +goose StatementEnd
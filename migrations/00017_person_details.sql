@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Asmens informacija iš TMDB /person/{id}. Parsiunčiama tik atidarius asmens puslapį;
+-- details_fetched_at IS NULL - dar nesiųsta.
+ALTER TABLE person
+    ADD COLUMN biography TEXT,
+    ADD COLUMN birthday DATE,
+    ADD COLUMN deathday DATE,
+    ADD COLUMN place_of_birth VARCHAR(255),
+    ADD COLUMN known_for_department VARCHAR(100),
+    ADD COLUMN details_fetched_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE person
+    DROP COLUMN IF EXISTS details_fetched_at,
+    DROP COLUMN IF EXISTS known_for_department,
+    DROP COLUMN IF EXISTS place_of_birth,
+    DROP COLUMN IF EXISTS deathday,
+    DROP COLUMN IF EXISTS birthday,
+    DROP COLUMN IF EXISTS biography;
+-- +goose StatementEnd
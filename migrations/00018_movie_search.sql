@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Trigramos paieškai su rašybos klaidomis ("incepton" -> "Inception")
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Pavadinimas svarbesnis už aprašymą (A > B reitinguojant)
+ALTER TABLE movie ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english'::regconfig, COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('english'::regconfig, COALESCE(overview, '')), 'B')
+) STORED;
+
+CREATE INDEX idx_movie_search_vector ON movie USING GIN (search_vector);
+CREATE INDEX idx_movie_title_trgm ON movie USING GIN (title gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_movie_title_trgm;
+DROP INDEX IF EXISTS idx_movie_search_vector;
+ALTER TABLE movie DROP COLUMN IF EXISTS search_vector;
+-- pg_trgm paliekamas - jį gali naudoti ir kiti objektai
+-- +goose StatementEnd
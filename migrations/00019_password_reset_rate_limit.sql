@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- login_lockout skaitliukai naudojami ir slaptažodžio atkūrimo užklausoms riboti
+ALTER TABLE login_lockout DROP CONSTRAINT login_lockout_scope_check;
+ALTER TABLE login_lockout ADD CONSTRAINT login_lockout_scope_check
+    CHECK (scope IN ('account', 'ip', 'reset_email', 'reset_ip'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM login_lockout WHERE scope IN ('reset_email', 'reset_ip');
+ALTER TABLE login_lockout DROP CONSTRAINT login_lockout_scope_check;
+ALTER TABLE login_lockout ADD CONSTRAINT login_lockout_scope_check
+    CHECK (scope IN ('account', 'ip'));
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEvent - privilegijuoto veiksmo įrašas (rolės keitimas, filmo trynimas, ...)
+type AuditEvent struct {
+	AuditEventID uuid.UUID       `json:"audit_event_id" db:"audit_event_id"`
+	ActorID      *uuid.UUID      `json:"actor_id" db:"actor_id"`
+	ActorEmail   string          `json:"actor_email" db:"actor_email"`
+	Action       string          `json:"action" db:"action"`
+	TargetType   string          `json:"target_type" db:"target_type"`
+	TargetID     string          `json:"target_id" db:"target_id"`
+	Changes      json.RawMessage `json:"changes" db:"changes"`
+	IPAddress    string          `json:"ip_address" db:"ip_address"`
+	RequestID    string          `json:"request_id" db:"request_id"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditFilter - audito paieškos filtrai. Tušti laukai neriboja.
+type AuditFilter struct {
+	Actor  string // vartotojo ID arba email
+	Action string
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+	Offset int
+}
@@ -6,12 +6,12 @@ import (
 	"github.com/google/uuid"
 )
 
-// OAuth saugo Google prisijungimo duomenis
+// OAuth saugo išorinio tiekėjo (Google, GitHub, OIDC) prisijungimo duomenis
 type OAuth struct {
 	OAuthProviderID uuid.UUID `db:"oauth_provider_id"`
 	UserID          uuid.UUID `db:"user_id"`
-	Provider        string    `db:"provider"`    // 'google'
-	ProviderID      string    `db:"provider_id"` // Google ID
+	Provider        string    `db:"provider"`    // 'google', 'github', ...
+	ProviderID      string    `db:"provider_id"` // ID pas tiekėją
 	ProviderEmail   string    `db:"provider_email"`
 	AccessToken     string    `db:"access_token"`
 	RefreshToken    string    `db:"refresh_token"`
@@ -38,3 +38,9 @@ type TokenResponse struct {
 	Scope        string `json:"scope"`
 	IDToken      string `json:"id_token"`
 }
+
+// OAuthProvider - sukonfigūruotas tiekėjas, rodomas prisijungimo ir profilio puslapiuose
+type OAuthProvider struct {
+	Name        string
+	DisplayName string
+}
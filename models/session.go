@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserSession - aktyvi prisijungimo sesija, rodoma /profile/sessions puslapyje
+type UserSession struct {
+	SessionID  uuid.UUID `json:"session_id" db:"session_id"`
+	DeviceInfo string    `json:"device_info" db:"device_info"`
+	IPAddress  string    `json:"ip_address" db:"ip_address"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at" db:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
+	Current    bool      `json:"current" db:"-"` // ar tai sesija, iš kurios daroma užklausa
+}
+
+// LoginAttempt - įrašas apie prisijungimo bandymą
+type LoginAttempt struct {
+	Email     string     `json:"email" db:"email"`
+	UserID    *uuid.UUID `json:"user_id" db:"user_id"`
+	IPAddress string     `json:"ip_address" db:"ip_address"`
+	UserAgent string     `json:"user_agent" db:"user_agent"`
+	Success   bool       `json:"success" db:"success"`
+	Reason    string     `json:"reason" db:"reason"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserSuspension - paskyros suspendavimas su priežastimi ir (nebūtina) pabaiga
+type UserSuspension struct {
+	SuspensionID     uuid.UUID  `json:"suspension_id" db:"suspension_id"`
+	UserID           uuid.UUID  `json:"user_id" db:"user_id"`
+	Reason           string     `json:"reason" db:"reason"`
+	SuspendedBy      *uuid.UUID `json:"suspended_by" db:"suspended_by"`
+	SuspendedByEmail string     `json:"suspended_by_email" db:"-"`
+	SuspendedAt      time.Time  `json:"suspended_at" db:"suspended_at"`
+	ExpiresAt        *time.Time `json:"expires_at" db:"expires_at"` // nil - neterminuota
+	LiftedAt         *time.Time `json:"lifted_at" db:"lifted_at"`
+}
+
+// Expired - ar terminuota suspensija jau pasibaigė (bet dar nenuimta)
+func (s *UserSuspension) Expired() bool {
+	return s.ExpiresAt != nil && !s.ExpiresAt.After(time.Now())
+}
+
+// SuspendedUser - suspenduotas vartotojas su aktyvia suspensija
+type SuspendedUser struct {
+	User       User           `json:"user"`
+	Suspension UserSuspension `json:"suspension"`
+}
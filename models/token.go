@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"battleNet/permissions"
+
+	"github.com/google/uuid"
+)
+
+// Personal access token scope'ai
+const (
+	ScopeReviewsWrite   = "reviews:write"
+	ScopeWatchlistRead  = "watchlist:read"
+	ScopeWatchlistWrite = "watchlist:write"
+	ScopeMoviesAdmin    = "movies:admin"
+	ScopeUsersModerate  = "users:moderate"
+	ScopeAuditRead      = "audit:read"
+)
+
+// TokenScope - scope'as su aprašymu ir leidimu, kurio reikia jam suteikti
+type TokenScope struct {
+	Name        string
+	Description string
+	Permission  string // tuščias - prieinama visiems vartotojams
+}
+
+// TokenScopes - visi scope'ai, kuriuos galima suteikti personal access token'ui
+var TokenScopes = []TokenScope{
+	{Name: ScopeReviewsWrite, Description: "Write reviews"},
+	{Name: ScopeWatchlistRead, Description: "Read your watchlist"},
+	{Name: ScopeWatchlistWrite, Description: "Add and remove watchlist movies"},
+	{Name: ScopeMoviesAdmin, Description: "Create, update and delete movies", Permission: permissions.MovieCreate},
+	{Name: ScopeUsersModerate, Description: "Manage users", Permission: permissions.UserView},
+	{Name: ScopeAuditRead, Description: "Read the audit log", Permission: permissions.AuditView},
+}
+
+// PersonalAccessToken - ilgalaikis API token'as automatizacijai
+type PersonalAccessToken struct {
+	TokenID     uuid.UUID  `json:"token_id" db:"token_id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Name        string     `json:"name" db:"name"`
+	TokenPrefix string     `json:"token_prefix" db:"token_prefix"`
+	Scopes      []string   `json:"scopes" db:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at" db:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at" db:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
@@ -36,8 +36,163 @@ type Movie struct {
 	Runtime      *int       `json:"runtime" db:"runtime"`
 	Status       *string    `json:"status" db:"status"`
 	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	Genres       []Genre    `json:"genres" db:"-"`
+
+	// Užpildomi tik filmų sąraše (ListMovies): mūsų viešų recenzijų vidurkis ir skaičius
+	CommunityRating *float64 `json:"community_rating,omitempty" db:"-"`
+	ReviewCount     *int     `json:"review_count,omitempty" db:"-"`
+
+	// Highlight užpildomas tik paieškos rezultatuose (MovieFilter.Query)
+	Highlight *MovieHighlight `json:"highlight,omitempty" db:"-"`
+}
+
+// MovieHighlight - paieškos rezultato ištraukos kaip HTML: tekstas escape'intas,
+// sutapę žodžiai apgaubti <mark>, todėl saugu įterpti be papildomo apdorojimo.
+type MovieHighlight struct {
+	Title    string `json:"title"`
+	Overview string `json:"overview"`
+}
+
+// Genre - žanras iš genre lentelės (filmams priskiriamas per movie_genre)
+type Genre struct {
+	GenreID uuid.UUID `json:"genre_id" db:"genre_id"`
+	Name    string    `json:"name" db:"name"`
+}
+
+// Person - aktorius ar kūrybinės komandos narys (person lentelė)
+type Person struct {
+	PersonID    uuid.UUID `json:"person_id" db:"person_id"`
+	TmdbID      *int      `json:"tmdb_id" db:"tmdb_id"`
+	Name        string    `json:"name" db:"name"`
+	ProfilePath *string   `json:"profile_path" db:"profile_path"`
+}
+
+// PersonDetails - asmuo su TMDB informacija (asmens puslapiui)
+type PersonDetails struct {
+	Person
+	Biography          *string    `json:"biography" db:"biography"`
+	Birthday           *time.Time `json:"birthday" db:"birthday"`
+	Deathday           *time.Time `json:"deathday" db:"deathday"`
+	PlaceOfBirth       *string    `json:"place_of_birth" db:"place_of_birth"`
+	KnownForDepartment *string    `json:"known_for_department" db:"known_for_department"`
+	DetailsFetchedAt   *time.Time `json:"-" db:"details_fetched_at"`
+}
+
+// FilmographyCredit - filmas asmens filmografijoje su mūsų recenzijų vidurkiu
+type FilmographyCredit struct {
+	MovieID       uuid.UUID  `json:"movie_id"`
+	Title         string     `json:"title"`
+	ReleaseDate   *time.Time `json:"release_date"`
+	PosterPath    *string    `json:"poster_path"`
+	Character     string     `json:"character,omitempty"` // tik vaidmenims; keli vaidmenys per " / "
+	AverageRating *float64   `json:"average_rating"`      // viešų recenzijų vidurkis (1-10), nil - recenzijų nėra
+	ReviewCount   int        `json:"review_count"`
+}
+
+// FilmographyJob - vienos pareigos (pvz. Director) filmai
+type FilmographyJob struct {
+	Department string              `json:"department"`
+	Job        string              `json:"job"`
+	Movies     []FilmographyCredit `json:"movies"`
+}
+
+// Filmography - asmens filmai mūsų kataloge; filmai naujausi pirmi
+type Filmography struct {
+	Acting []FilmographyCredit `json:"acting"`
+	Crew   []FilmographyJob    `json:"crew"`
+}
+
+// CastCredit - vaidmuo filme
+type CastCredit struct {
+	Person
+	Character string `json:"character" db:"character_name"`
+	Order     int    `json:"order" db:"billing_order"`
+}
+
+// CrewCredit - pareigos filmo komandoje (Director, Screenplay, ...)
+type CrewCredit struct {
+	Person
+	Department string `json:"department" db:"department"`
+	Job        string `json:"job" db:"job"`
 }
 
+// MovieCredits - filmo aktoriai (pagal billing order) ir komanda
+type MovieCredits struct {
+	Cast []CastCredit `json:"cast"`
+	Crew []CrewCredit `json:"crew"`
+}
+
+// Directors - komandos nariai, kurių pareigos "Director"
+func (c MovieCredits) Directors() []CrewCredit {
+	var directors []CrewCredit
+	for _, member := range c.Crew {
+		if member.Job == "Director" {
+			directors = append(directors, member)
+		}
+	}
+	return directors
+}
+
+// MovieFilter - filmų sąrašo filtras (/movies, /api/v1/movies). nil/tušti laukai neriboja.
+type MovieFilter struct {
+	Query          string   // pilno teksto paieška pavadinime ir aprašyme; rezultatai rikiuojami pagal atitikimą
+	Genres         []string // žanrų pavadinimai (be didžiųjų raidžių skirtumo); filmas turi turėti bent vieną
+	Statuses       []string // filmas turi turėti vieną iš jų
+	YearFrom       *int     // išleidimo metai imtinai
+	YearTo         *int
+	RuntimeMin     *int // minutės imtinai
+	RuntimeMax     *int
+	MinVoteAverage *float64 // TMDB vote_average
+	MinRating      *float64 // mūsų viešų recenzijų vidurkis; filmai be recenzijų neatitinka
+	Sort           string   // MovieSorts; tuščias - relevance su paieška, kitaip created_at
+	Descending     bool
+	Limit          int
+	Offset         int
+}
+
+// MovieSorts - leidžiamos /movies sort reikšmės
+var MovieSorts = []string{"relevance", "created_at", "popularity", "release_date", "title", "rating", "community_rating", "reviews"}
+
+// UserSorts - leidžiamos moderatoriaus vartotojų sąrašo sort reikšmės
+var UserSorts = []string{"email", "username", "name", "role", "created_at", "last_login_at"}
+
+// FacetCount - reikšmė (žanras, statusas) ir kiek filmų ją turi
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// RangeFacet - intervalas (imtinai; nil - neribotas) ir kiek filmų į jį patenka
+type RangeFacet struct {
+	Min   *int `json:"min"`
+	Max   *int `json:"max"`
+	Count int  `json:"count"`
+}
+
+// MovieFacets - kiek filmų atitiktų kiekvieną filtro reikšmę. Skaičiuojama su
+// visais kitais filtrais, bet be paties facet'o filtro (pvz. žanrų skaičiai
+// nepriklauso nuo pažymėtų žanrų), kad būtų matyti, ką duotų kitas pasirinkimas.
+type MovieFacets struct {
+	Genres         []FacetCount `json:"genres"`
+	Statuses       []FacetCount `json:"statuses"`
+	Decades        []RangeFacet `json:"decades"`          // year_from/year_to
+	Runtimes       []RangeFacet `json:"runtimes"`         // runtime_min/runtime_max
+	MinVoteAverage []RangeFacet `json:"min_vote_average"` // tik Min; min_vote
+	MinRating      []RangeFacet `json:"min_rating"`       // tik Min; min_rating
+}
+
+// GenreNames - filmo žanrų pavadinimai
+func (m Movie) GenreNames() []string {
+	names := make([]string, len(m.Genres))
+	for i, genre := range m.Genres {
+		names[i] = genre.Name
+	}
+	return names
+}
+
+// MovieStatuses - leidžiamos filmo status reikšmės (kaip TMDB)
+var MovieStatuses = []string{"Released", "Post Production", "In Production", "Planned", "Rumored", "Cancelled"}
+
 type Review struct {
 	ReviewID         uuid.UUID `json:"review_id" db:"review_id"`
 	UserID           uuid.UUID `json:"user_id" db:"user_id"`
@@ -101,3 +256,17 @@ type UpdateReviewParams struct {
 	ContainsSpoilers bool
 	IsPublic         bool
 }
+
+// UserFilter - moderatoriaus vartotojų paieškos parametrai. nil/tušti laukai neriboja.
+type UserFilter struct {
+	Query         string // ieškoma email, username, varde ir pavardėje
+	Role          string
+	Active        *bool
+	EmailVerified *bool
+	LastLoginFrom *time.Time
+	LastLoginTo   *time.Time
+	Sort          string // UserSorts
+	Descending    bool
+	Limit         int
+	Offset        int
+}
@@ -0,0 +1,85 @@
+// Package openapi serves the hand-written OpenAPI 3.1 description of /api/v1
+// and checks it against the router, so a new route can't silently go
+// undocumented.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+// Spec grąžina OpenAPI dokumentą (JSON)
+func Spec() []byte {
+	return spec
+}
+
+// Handler - GET /api/v1/openapi.json
+func Handler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(spec)
+}
+
+// Undocumented grąžina maršrutus po prefix (pvz. "/api/v1"), kurių nėra
+// specifikacijoje, ir specifikacijos operacijas, kurių nėra routeryje.
+// Formatas: "GET /movies/{id}" (kelias be prefix, kaip specifikacijoje).
+func Undocumented(router chi.Routes, prefix string) (missing, stale []string, err error) {
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, nil, fmt.Errorf("parse openapi.json: %w", err)
+	}
+
+	documented := make(map[string]bool)
+	for path, item := range doc.Paths {
+		for method := range item {
+			if method == "parameters" || method == "summary" || method == "description" {
+				continue
+			}
+			documented[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+
+	routed := make(map[string]bool)
+	err = chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		path, ok := strings.CutPrefix(route, prefix)
+		if !ok || method == http.MethodHead || method == http.MethodOptions {
+			return nil
+		}
+		// chi subrouteriai gali palikti "/*" arba galinį "/"
+		path = strings.TrimSuffix(strings.TrimSuffix(path, "/*"), "/")
+		if path == "" {
+			return nil
+		}
+
+		key := method + " " + path
+		routed[key] = true
+		if !documented[key] {
+			missing = append(missing, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for key := range documented {
+		if !routed[key] {
+			stale = append(stale, key)
+		}
+	}
+
+	sort.Strings(missing)
+	sort.Strings(stale)
+	return missing, stale, nil
+}
@@ -0,0 +1,43 @@
+package openapi_test
+
+import (
+	"testing"
+
+	"battleNet/config"
+	"battleNet/internal/handlers"
+	"battleNet/internal/router"
+	"battleNet/openapi"
+	"battleNet/permissions"
+	"battleNet/repository"
+
+	"github.com/alexedwards/scs/v2"
+)
+
+// Kiekvienas /api/v1 maršrutas turi būti aprašytas openapi.json ir atvirkščiai.
+// Routeris tik sukuriamas, užklausos nesiunčiamos, todėl DB nereikia.
+func TestSpecCoversRoutes(t *testing.T) {
+	policy, err := permissions.NewPolicy([]permissions.Role{{Name: "user"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm := scs.New()
+	handler := handlers.NewHandler(handlers.Deps{
+		Repos:          &repository.Repositories{},
+		SessionManager: sm,
+		Policy:         policy,
+		JWTSecret:      "test-secret",
+		TokenSecret:    "test-token-secret",
+	})
+	mux := router.New(handler, sm, policy, &config.Config{})
+
+	missing, stale, err := openapi.Undocumented(mux, "/api/v1")
+	if err != nil {
+		t.Fatalf("Undocumented: %v", err)
+	}
+	for _, route := range missing {
+		t.Errorf("route %s is missing from openapi.json", route)
+	}
+	for _, route := range stale {
+		t.Errorf("openapi.json documents %s, but the router has no such route", route)
+	}
+}
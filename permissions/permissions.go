@@ -0,0 +1,117 @@
+// Package permissions maps roles to named permissions. Roles form a
+// hierarchy: a role holds its own permissions plus everything its parent
+// role holds. The mapping itself lives in the role, permission and
+// role_permission tables and is loaded at startup.
+package permissions
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Leidimų pavadinimai. Nauji leidimai turi būti įrašyti ir į permission lentelę.
+const (
+	MovieCreate = "movie.create"
+	MovieUpdate = "movie.update"
+	MovieDelete = "movie.delete"
+	MovieImport = "movie.import"
+
+	UserView       = "user.view"
+	UserRoleUpdate = "user.role.update"
+	UserDeactivate = "user.deactivate"
+	UserUnlock     = "user.unlock"
+
+	ReviewModerate = "review.moderate"
+
+	AuditView = "audit.view"
+)
+
+// Role - rolės aprašas, kaip jis saugomas DB
+type Role struct {
+	Name        string
+	Parent      string // tuščias - rolė nieko nepaveldi
+	Permissions []string
+}
+
+// Policy holds the effective (inherited) permissions of every role
+type Policy struct {
+	roles map[string]map[string]bool
+	order []string
+}
+
+// NewPolicy resolves inheritance for roles. An unknown parent or an
+// inheritance cycle is an error.
+func NewPolicy(roles []Role) (*Policy, error) {
+	defs := make(map[string]Role, len(roles))
+	for _, role := range roles {
+		defs[role.Name] = role
+	}
+
+	p := &Policy{roles: make(map[string]map[string]bool, len(roles))}
+	for _, role := range roles {
+		effective := make(map[string]bool)
+		seen := make(map[string]bool)
+
+		for name := role.Name; name != ""; name = defs[name].Parent {
+			if seen[name] {
+				return nil, fmt.Errorf("role %q: inheritance cycle through %q", role.Name, name)
+			}
+			seen[name] = true
+
+			def, ok := defs[name]
+			if !ok {
+				return nil, fmt.Errorf("role %q: unknown parent role %q", role.Name, name)
+			}
+			for _, permission := range def.Permissions {
+				effective[permission] = true
+			}
+		}
+
+		p.roles[role.Name] = effective
+	}
+
+	// Rolės rikiuojamos nuo mažiausiai iki daugiausiai leidimų turinčios
+	for name := range p.roles {
+		p.order = append(p.order, name)
+	}
+	sort.Slice(p.order, func(i, j int) bool {
+		a, b := p.order[i], p.order[j]
+		if len(p.roles[a]) != len(p.roles[b]) {
+			return len(p.roles[a]) < len(p.roles[b])
+		}
+		return a < b
+	})
+
+	return p, nil
+}
+
+// Can reports whether role holds permission
+func (p *Policy) Can(role, permission string) bool {
+	return p.roles[role][permission]
+}
+
+// Exists reports whether role is defined
+func (p *Policy) Exists(role string) bool {
+	_, ok := p.roles[role]
+	return ok
+}
+
+// Covers reports whether actor holds every permission of role, i.e. whether
+// an actor may hand out role or manage users that have it.
+func (p *Policy) Covers(actor, role string) bool {
+	held, ok := p.roles[role]
+	if !ok {
+		return false
+	}
+	for permission := range held {
+		if !p.Can(actor, permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// Roles returns all role names, least privileged first
+func (p *Policy) Roles() []string {
+	return append([]string(nil), p.order...)
+}
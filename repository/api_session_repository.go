@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"battleNet/models"
+	"context"
+	"net/netip"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APISessionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAPISessionRepository(pool *pgxpool.Pool) *APISessionRepository {
+	return &APISessionRepository{pool: pool}
+}
+
+// CreateSession sukuria naują API sesiją ir grąžina jos ID
+func (r *APISessionRepository) CreateSession(ctx context.Context, userID uuid.UUID, refreshTokenHash string, expiresAt time.Time, twoFactor bool, deviceInfo, ipAddress string) (uuid.UUID, error) {
+	var ip *string
+	if addr, err := netip.ParseAddr(ipAddress); err == nil {
+		s := addr.String()
+		ip = &s
+	}
+
+	query := `
+		INSERT INTO api_session (user_id, refresh_token_hash, expires_at, two_factor, device_info, ip_address)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6::inet)
+		RETURNING session_id
+	`
+
+	var sessionID uuid.UUID
+	err := r.pool.QueryRow(ctx, query, userID, refreshTokenHash, expiresAt, twoFactor, deviceInfo, ip).Scan(&sessionID)
+	return sessionID, err
+}
+
+// RotateRefreshToken pakeičia refresh token'ą nauju. Senas token'as po to
+// nebegalioja. Returns pgx.ErrNoRows when the old token is unknown, revoked or expired.
+func (r *APISessionRepository) RotateRefreshToken(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (sessionID, userID uuid.UUID, err error) {
+	query := `
+		UPDATE api_session
+		SET refresh_token_hash = $2, expires_at = $3, last_used_at = NOW()
+		WHERE refresh_token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		RETURNING session_id, user_id
+	`
+
+	err = r.pool.QueryRow(ctx, query, oldHash, newHash, expiresAt).Scan(&sessionID, &userID)
+	return sessionID, userID, err
+}
+
+// GetSessionUser grąžina aktyvios sesijos vartotoją ir ar sesija sukurta su
+// 2FA. Neaktyvūs vartotojai ir atšauktos sesijos grąžina pgx.ErrNoRows.
+func (r *APISessionRepository) GetSessionUser(ctx context.Context, sessionID uuid.UUID) (*models.User, bool, error) {
+	query := `
+		SELECT u.user_id, u.email, u.first_name, u.last_name, u.username, u.role,
+		       u.is_active, u.email_verified, s.two_factor
+		FROM api_session s
+		JOIN "user" u ON u.user_id = s.user_id
+		WHERE s.session_id = $1 AND s.revoked_at IS NULL AND s.expires_at > NOW()
+		  AND u.is_active = true
+	`
+
+	var user models.User
+	var twoFactor bool
+	err := r.pool.QueryRow(ctx, query, sessionID).Scan(
+		&user.UserID, &user.Email, &user.FirstName, &user.LastName, &user.Username, &user.Role,
+		&user.IsActive, &user.EmailVerified, &twoFactor,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return &user, twoFactor, nil
+}
+
+// RevokeByRefreshToken atšaukia sesiją pagal refresh token'ą
+func (r *APISessionRepository) RevokeByRefreshToken(ctx context.Context, refreshTokenHash string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE api_session SET revoked_at = NOW()
+		WHERE refresh_token_hash = $1 AND revoked_at IS NULL
+	`, refreshTokenHash)
+	return err
+}
+
+// RevokeSession atšaukia sesiją pagal ID
+func (r *APISessionRepository) RevokeSession(ctx context.Context, sessionID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE api_session SET revoked_at = NOW()
+		WHERE session_id = $1 AND revoked_at IS NULL
+	`, sessionID)
+	return err
+}
+
+// RevokeAllForUser atšaukia visas vartotojo API sesijas (slaptažodžio keitimas, deaktyvavimas)
+func (r *APISessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE api_session SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID)
+	return err
+}
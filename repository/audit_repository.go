@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"battleNet/models"
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAuditRepository(pool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{pool: pool}
+}
+
+// RecordEvent įrašo audito įvykį
+func (r *AuditRepository) RecordEvent(ctx context.Context, event *models.AuditEvent) error {
+	var ip *string
+	if addr, err := netip.ParseAddr(event.IPAddress); err == nil {
+		s := addr.String()
+		ip = &s
+	}
+
+	changes := event.Changes
+	if len(changes) == 0 {
+		changes = []byte("{}")
+	}
+
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO audit_event (actor_id, actor_email, action, target_type, target_id, changes, ip_address, request_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7::inet, NULLIF($8, ''))
+		RETURNING audit_event_id, created_at
+	`, event.ActorID, event.ActorEmail, event.Action, event.TargetType, event.TargetID, changes, ip, event.RequestID,
+	).Scan(&event.AuditEventID, &event.CreatedAt)
+}
+
+// GetEvents grąžina įvykius nuo naujausių pagal filtrą
+func (r *AuditRepository) GetEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	var conditions []string
+	var args []any
+
+	if filter.Actor != "" {
+		if actorID, err := uuid.Parse(filter.Actor); err == nil {
+			args = append(args, actorID)
+			conditions = append(conditions, fmt.Sprintf("actor_id = $%d", len(args)))
+		} else {
+			args = append(args, filter.Actor)
+			conditions = append(conditions, fmt.Sprintf("LOWER(actor_email) = LOWER($%d)", len(args)))
+		}
+	}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		conditions = append(conditions, fmt.Sprintf("action = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	query := `
+		SELECT audit_event_id, actor_id, actor_email, action, target_type, COALESCE(target_id, ''),
+		       changes, COALESCE(host(ip_address), ''), COALESCE(request_id, ''), created_at
+		FROM audit_event`
+	if len(conditions) > 0 {
+		query += "\n\t\tWHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf("\n\t\tORDER BY created_at DESC\n\t\tLIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.AuditEvent
+	for rows.Next() {
+		var event models.AuditEvent
+		if err := rows.Scan(
+			&event.AuditEventID,
+			&event.ActorID,
+			&event.ActorEmail,
+			&event.Action,
+			&event.TargetType,
+			&event.TargetID,
+			&event.Changes,
+			&event.IPAddress,
+			&event.RequestID,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetActions grąžina visus audito įvykių tipus (filtro pasirinkimui)
+func (r *AuditRepository) GetActions(ctx context.Context) ([]string, error) {
+	rows, err := r.pool.Query(ctx, `SELECT DISTINCT action FROM audit_event ORDER BY action`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []string
+	for rows.Next() {
+		var action string
+		if err := rows.Scan(&action); err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, rows.Err()
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"battleNet/models"
+	"context"
+	"net/netip"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Lockout scope'ai
+const (
+	LockoutScopeAccount = "account"
+	LockoutScopeIP      = "ip"
+
+	// Slaptažodžio atkūrimo užklausų skaitliukai (blokuotės nenaudoja)
+	LockoutScopeResetEmail = "reset_email"
+	LockoutScopeResetIP    = "reset_ip"
+)
+
+type LoginAttemptRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLoginAttemptRepository(pool *pgxpool.Pool) *LoginAttemptRepository {
+	return &LoginAttemptRepository{pool: pool}
+}
+
+// RecordAttempt įrašo prisijungimo bandymą į auditą
+func (r *LoginAttemptRepository) RecordAttempt(ctx context.Context, attempt models.LoginAttempt) error {
+	var ip *string
+	if addr, err := netip.ParseAddr(attempt.IPAddress); err == nil {
+		s := addr.String()
+		ip = &s
+	}
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO login_attempt (email, user_id, ip_address, user_agent, success, reason)
+		VALUES ($1, $2, $3::inet, NULLIF($4, ''), $5, NULLIF($6, ''))
+	`, attempt.Email, attempt.UserID, ip, attempt.UserAgent, attempt.Success, attempt.Reason)
+	return err
+}
+
+// LockedUntil grąžina vėliausią galiojančią blokuotę iš nurodytų subjektų
+// (pvz. paskyros ir IP). Nulinis laikas - blokuotės nėra.
+func (r *LoginAttemptRepository) LockedUntil(ctx context.Context, account, ip string) (time.Time, error) {
+	var lockedUntil *time.Time
+	err := r.pool.QueryRow(ctx, `
+		SELECT MAX(locked_until)
+		FROM login_lockout
+		WHERE ((scope = 'account' AND subject = $1) OR (scope = 'ip' AND subject = $2))
+		  AND locked_until > NOW()
+	`, account, ip).Scan(&lockedUntil)
+	if err != nil || lockedUntil == nil {
+		return time.Time{}, err
+	}
+	return *lockedUntil, nil
+}
+
+// RegisterFailure padidina nesėkmių skaitliuką. Jei paskutinė nesėkmė buvo
+// senesnė nei window, skaičiuojama iš naujo. Grąžina nesėkmių skaičių.
+func (r *LoginAttemptRepository) RegisterFailure(ctx context.Context, scope, subject string, window time.Duration) (int, error) {
+	var failures int
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO login_lockout (scope, subject, failures, last_failure_at)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (scope, subject) DO UPDATE
+		SET failures = CASE
+		        WHEN login_lockout.last_failure_at < NOW() - make_interval(secs => $3) THEN 1
+		        ELSE login_lockout.failures + 1
+		    END,
+		    last_failure_at = NOW()
+		RETURNING failures
+	`, scope, subject, window.Seconds()).Scan(&failures)
+	return failures, err
+}
+
+// Lock užblokuoja subjektą iki nurodyto laiko
+func (r *LoginAttemptRepository) Lock(ctx context.Context, scope, subject string, until time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE login_lockout SET locked_until = $3 WHERE scope = $1 AND subject = $2
+	`, scope, subject, until)
+	return err
+}
+
+// Clear pašalina subjekto nesėkmes ir blokuotę (sėkmingas prisijungimas, moderatoriaus atblokavimas)
+func (r *LoginAttemptRepository) Clear(ctx context.Context, scope, subject string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM login_lockout WHERE scope = $1 AND subject = $2`, scope, subject)
+	return err
+}
+
+// GetAccountLockouts grąžina šiuo metu užblokuotas paskyras: email -> iki kada
+func (r *LoginAttemptRepository) GetAccountLockouts(ctx context.Context) (map[string]time.Time, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT subject, locked_until
+		FROM login_lockout
+		WHERE scope = 'account' AND locked_until > NOW()
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lockouts := make(map[string]time.Time)
+	for rows.Next() {
+		var email string
+		var lockedUntil time.Time
+		if err := rows.Scan(&email, &lockedUntil); err != nil {
+			return nil, err
+		}
+		lockouts[email] = lockedUntil
+	}
+
+	return lockouts, rows.Err()
+}
@@ -3,49 +3,370 @@ package repository
 import (
 	"battleNet/models"
 	"context"
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// ErrDuplicateImdbID - filmas su tokiu IMDB ID jau yra
+var ErrDuplicateImdbID = errors.New("movie with this IMDB ID already exists")
+
 type MovieRepository struct {
 	pool *pgxpool.Pool
 }
 
+// duplicateImdbID pakeičia unique pažeidimą į ErrDuplicateImdbID
+// (vienintelis unikalus movie stulpelis be PK yra imdb_id)
+func duplicateImdbID(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrDuplicateImdbID
+	}
+	return err
+}
+
 func NewMovieRepository(pool *pgxpool.Pool) *MovieRepository {
 	return &MovieRepository{pool: pool}
 }
 
 func (r *MovieRepository) GetMovies(ctx context.Context, limit, offset int32) ([]models.Movie, error) {
-	query := `
-		SELECT movie_id, imdb_id, title, overview, release_date, poster_path,
-		       backdrop_path, vote_average, vote_count, popularity, runtime, status, created_at
-		FROM movie
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2
-	`
+	movies, _, err := r.ListMovies(ctx, models.MovieFilter{Limit: int(limit), Offset: int(offset)})
+	return movies, err
+}
+
+// Paieškos žymekliai ts_headline rezultate. Tekstas escape'inamas Go pusėje,
+// tik tada žymekliai pakeičiami <mark> (ts_headline pats HTML neescape'ina).
+const (
+	highlightStart = "\u27e6"
+	highlightStop  = "\u27e7"
+)
+
+var highlightReplacer = strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>")
+
+// highlightHTML - ts_headline rezultatas -> saugus HTML su <mark>
+func highlightHTML(headline string) string {
+	return highlightReplacer.Replace(html.EscapeString(headline))
+}
+
+// movieFrom - filmai su mūsų viešų recenzijų statistika (rs), kurią naudoja
+// min_rating filtras, rikiavimas ir sąrašas
+const movieFrom = `movie m
+		LEFT JOIN (
+			SELECT movie_id, AVG(rating)::float8 AS average_rating, COUNT(*)::int AS review_count
+			FROM review
+			WHERE is_public = true
+			GROUP BY movie_id
+		) rs ON rs.movie_id = m.movie_id`
+
+// Facet'ai, kurių filtras praleidžiamas skaičiuojant jų pačių reikšmes
+const (
+	facetNone    = ""
+	facetGenre   = "genre"
+	facetStatus  = "status"
+	facetYear    = "year"
+	facetRuntime = "runtime"
+	facetVote    = "vote"
+	facetRating  = "rating"
+)
+
+// movieFilterWhere sudaro WHERE sąlygą ir argumentus pagal filtrą, praleisdama
+// skip facet'o sąlygą. Jei filtre yra paieška, queryArg - jos argumento numeris ($n), kitaip 0.
+func movieFilterWhere(filter models.MovieFilter, skip string) (where string, args []any, queryArg int) {
+	var conditions []string
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if query := strings.TrimSpace(filter.Query); query != "" {
+		args = append(args, query)
+		queryArg = len(args)
+		// Pilno teksto atitikimas arba panašus pavadinimas (rašybos klaidos, dalis žodžio)
+		conditions = append(conditions, fmt.Sprintf(
+			"(m.search_vector @@ websearch_to_tsquery('english', $%[1]d) OR m.title %% $%[1]d OR $%[1]d <%% m.title)",
+			queryArg))
+	}
+	if len(filter.Genres) > 0 && skip != facetGenre {
+		names := make([]string, len(filter.Genres))
+		for i, name := range filter.Genres {
+			names[i] = strings.ToLower(name)
+		}
+		add(`EXISTS (
+			SELECT 1 FROM movie_genre mg JOIN genre g ON g.genre_id = mg.genre_id
+			WHERE mg.movie_id = m.movie_id AND lower(g.name) = ANY($%d::text[])
+		)`, names)
+	}
+	if len(filter.Statuses) > 0 && skip != facetStatus {
+		add("m.status = ANY($%d::text[])", filter.Statuses)
+	}
+	if skip != facetYear {
+		if filter.YearFrom != nil {
+			add("m.release_date >= make_date($%d, 1, 1)", *filter.YearFrom)
+		}
+		if filter.YearTo != nil {
+			add("m.release_date < make_date($%d + 1, 1, 1)", *filter.YearTo)
+		}
+	}
+	if skip != facetRuntime {
+		if filter.RuntimeMin != nil {
+			add("m.runtime >= $%d", *filter.RuntimeMin)
+		}
+		if filter.RuntimeMax != nil {
+			add("m.runtime <= $%d", *filter.RuntimeMax)
+		}
+	}
+	if filter.MinVoteAverage != nil && skip != facetVote {
+		add("m.vote_average >= $%d", *filter.MinVoteAverage)
+	}
+	if filter.MinRating != nil && skip != facetRating {
+		add("rs.average_rating >= $%d", *filter.MinRating)
+	}
+
+	if len(conditions) == 0 {
+		return "", args, queryArg
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args, queryArg
+}
+
+// movieSortColumns - leidžiami rikiavimo stulpeliai (SQL injekcijos apsauga);
+// relevance atskirai, nes priklauso nuo paieškos
+var movieSortColumns = map[string]string{
+	"created_at":       "m.created_at",
+	"popularity":       "m.popularity",
+	"release_date":     "m.release_date",
+	"title":            "lower(m.title)",
+	"rating":           "m.vote_average",
+	"community_rating": "rs.average_rating",
+	"reviews":          "COALESCE(rs.review_count, 0)",
+}
+
+// ListMovies grąžina filmų puslapį pagal filtrą (su žanrais ir recenzijų statistika)
+// ir bendrą atitinkančių filmų skaičių. Be Sort - su paieška pagal atitikimą,
+// kitaip naujausi pirmi. Su paieška kiekvienas filmas turi Highlight.
+func (r *MovieRepository) ListMovies(ctx context.Context, filter models.MovieFilter) ([]models.Movie, int, error) {
+	where, args, queryArg := movieFilterWhere(filter, facetNone)
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM `+movieFrom+` `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+	// Nežinomas ar tuščias sort - naujausi pirmi
+	orderBy := "m.created_at DESC, m.movie_id"
+	if column, ok := movieSortColumns[filter.Sort]; ok {
+		orderBy = column + " " + direction + " NULLS LAST, " + orderBy
+	}
+
+	headlines := ""
+	if queryArg > 0 {
+		tsquery := fmt.Sprintf("websearch_to_tsquery('english', $%d)", queryArg)
+		selectors := fmt.Sprintf("StartSel=%s, StopSel=%s", highlightStart, highlightStop)
+		headlines = fmt.Sprintf(`,
+		       ts_headline('english', m.title, %[1]s, 'HighlightAll=true, %[2]s'),
+		       ts_headline('english', COALESCE(m.overview, ''), %[1]s, 'MaxWords=35, MinWords=15, %[2]s')`,
+			tsquery, selectors)
+		// Pavadinimo žodžiai sveria daugiau (setweight A); panašumas leidžia
+		// iškelti "incepton" -> "Inception", kai pilno teksto atitikimo nėra
+		if filter.Sort == "relevance" || filter.Sort == "" {
+			orderBy = fmt.Sprintf(
+				"ts_rank_cd(m.search_vector, %s) + word_similarity($%d, m.title) DESC, m.created_at DESC, m.movie_id",
+				tsquery, queryArg)
+		}
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`
+		SELECT m.movie_id, m.imdb_id, m.title, m.overview, m.release_date, m.poster_path,
+		       m.backdrop_path, m.vote_average, m.vote_count, m.popularity, m.runtime, m.status, m.created_at,
+		       rs.average_rating, COALESCE(rs.review_count, 0)%s
+		FROM %s
+		%s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, headlines, movieFrom, where, orderBy, len(args)-1, len(args))
 
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
 	var movies []models.Movie
 	for rows.Next() {
 		var movie models.Movie
-		err := rows.Scan(
+		dest := []any{
 			&movie.MovieID, &movie.ImdbID, &movie.Title, &movie.Overview, &movie.ReleaseDate,
 			&movie.PosterPath, &movie.BackdropPath, &movie.VoteAverage, &movie.VoteCount,
 			&movie.Popularity, &movie.Runtime, &movie.Status, &movie.CreatedAt,
-		)
-		if err != nil {
-			return nil, err
+			&movie.CommunityRating, &movie.ReviewCount,
+		}
+		var titleHeadline, overviewHeadline string
+		if queryArg > 0 {
+			dest = append(dest, &titleHeadline, &overviewHeadline)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, 0, err
+		}
+		if queryArg > 0 {
+			movie.Highlight = &models.MovieHighlight{
+				Title:    highlightHTML(titleHeadline),
+				Overview: highlightHTML(overviewHeadline),
+			}
 		}
 		movies = append(movies, movie)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.loadGenres(ctx, movies); err != nil {
+		return nil, 0, err
+	}
+	return movies, total, nil
+}
+
+// Facet'ų intervalai: trukmė minutėmis ir minimalūs reitingai (abu 0-10 skalėje)
+var (
+	runtimeRanges = []models.RangeFacet{
+		{Max: intPtr(89)},
+		{Min: intPtr(90), Max: intPtr(119)},
+		{Min: intPtr(120), Max: intPtr(149)},
+		{Min: intPtr(150)},
+	}
+	ratingThresholds = []models.RangeFacet{{Min: intPtr(5)}, {Min: intPtr(6)}, {Min: intPtr(7)}, {Min: intPtr(8)}}
+)
+
+func intPtr(n int) *int {
+	return &n
+}
+
+// MovieFacets suskaičiuoja, kiek filmų atitiktų kiekvieną filtro reikšmę
+// (žr. models.MovieFacets). Visi žanrai ir statusai grąžinami, net jei skaičius 0.
+func (r *MovieRepository) MovieFacets(ctx context.Context, filter models.MovieFilter) (models.MovieFacets, error) {
+	var facets models.MovieFacets
+
+	where, args, _ := movieFilterWhere(filter, facetGenre)
+	rows, err := r.pool.Query(ctx, `
+		SELECT g.name, COUNT(fm.movie_id)
+		FROM genre g
+		LEFT JOIN movie_genre mg ON mg.genre_id = g.genre_id
+		LEFT JOIN (SELECT m.movie_id FROM `+movieFrom+` `+where+`) fm ON fm.movie_id = mg.movie_id
+		GROUP BY g.name
+		ORDER BY g.name
+	`, args...)
+	if err != nil {
+		return facets, err
+	}
+	if facets.Genres, err = scanFacetCounts(rows); err != nil {
+		return facets, err
+	}
+
+	where, args, _ = movieFilterWhere(filter, facetStatus)
+	rows, err = r.pool.Query(ctx, `SELECT COALESCE(m.status, ''), COUNT(*) FROM `+movieFrom+` `+where+` GROUP BY m.status`, args...)
+	if err != nil {
+		return facets, err
+	}
+	statusCounts, err := scanFacetCounts(rows)
+	if err != nil {
+		return facets, err
+	}
+	facets.Statuses = make([]models.FacetCount, len(models.MovieStatuses))
+	for i, status := range models.MovieStatuses {
+		facets.Statuses[i].Value = status
+		for _, counted := range statusCounts {
+			if counted.Value == status {
+				facets.Statuses[i].Count = counted.Count
+			}
+		}
+	}
+
+	// Dešimtmečiai, kuriuose yra bent vienas filmas, naujausi pirmi
+	where, args, _ = movieFilterWhere(filter, facetYear)
+	rows, err = r.pool.Query(ctx, `
+		SELECT EXTRACT(YEAR FROM m.release_date)::int / 10 * 10 AS decade, COUNT(*)
+		FROM `+movieFrom+` `+where+`
+		GROUP BY decade
+		ORDER BY decade DESC
+	`, args...)
+	if err != nil {
+		return facets, err
+	}
+	defer rows.Close()
+	facets.Decades = []models.RangeFacet{}
+	for rows.Next() {
+		var decade *int
+		var count int
+		if err := rows.Scan(&decade, &count); err != nil {
+			return facets, err
+		}
+		if decade != nil {
+			facets.Decades = append(facets.Decades, models.RangeFacet{Min: decade, Max: intPtr(*decade + 9), Count: count})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return facets, err
+	}
+
+	if facets.Runtimes, err = r.countRanges(ctx, filter, facetRuntime, "m.runtime", runtimeRanges); err != nil {
+		return facets, err
+	}
+	if facets.MinVoteAverage, err = r.countRanges(ctx, filter, facetVote, "m.vote_average", ratingThresholds); err != nil {
+		return facets, err
+	}
+	facets.MinRating, err = r.countRanges(ctx, filter, facetRating, "rs.average_rating", ratingThresholds)
+	return facets, err
+}
+
+// scanFacetCounts nuskaito (reikšmė, skaičius) eilutes ir uždaro rows
+func scanFacetCounts(rows pgx.Rows) ([]models.FacetCount, error) {
+	defer rows.Close()
+
+	counts := []models.FacetCount{}
+	for rows.Next() {
+		var count models.FacetCount
+		if err := rows.Scan(&count.Value, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
+
+// countRanges suskaičiuoja filmus kiekviename column intervale viena užklausa.
+// column ir intervalai - tik iš šio failo, ne iš vartotojo.
+func (r *MovieRepository) countRanges(ctx context.Context, filter models.MovieFilter, skip, column string, ranges []models.RangeFacet) ([]models.RangeFacet, error) {
+	where, args, _ := movieFilterWhere(filter, skip)
+
+	counts := make([]string, len(ranges))
+	for i, rng := range ranges {
+		var conditions []string
+		if rng.Min != nil {
+			conditions = append(conditions, fmt.Sprintf("%s >= %d", column, *rng.Min))
+		}
+		if rng.Max != nil {
+			conditions = append(conditions, fmt.Sprintf("%s <= %d", column, *rng.Max))
+		}
+		counts[i] = "COUNT(*) FILTER (WHERE " + strings.Join(conditions, " AND ") + ")"
+	}
 
-	return movies, nil
+	result := slices.Clone(ranges)
+	dest := make([]any, len(result))
+	for i := range result {
+		dest[i] = &result[i].Count
+	}
+	err := r.pool.QueryRow(ctx, `SELECT `+strings.Join(counts, ", ")+` FROM `+movieFrom+` `+where, args...).Scan(dest...)
+	return result, err
 }
 
 func (r *MovieRepository) GetMovieByID(ctx context.Context, movieID uuid.UUID) (*models.Movie, error) {
@@ -67,9 +388,122 @@ func (r *MovieRepository) GetMovieByID(ctx context.Context, movieID uuid.UUID) (
 		return nil, err
 	}
 
-	return &movie, nil
+	movies := []models.Movie{movie}
+	if err := r.loadGenres(ctx, movies); err != nil {
+		return nil, err
+	}
+	return &movies[0], nil
+}
+
+// loadGenres užpildo movies[i].Genres (abėcėlės tvarka) viena užklausa.
+// Filmas be žanrų gauna tuščią sąrašą, kad JSON būtų [], ne null.
+func (r *MovieRepository) loadGenres(ctx context.Context, movies []models.Movie) error {
+	if len(movies) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(movies))
+	index := make(map[uuid.UUID]int, len(movies))
+	for i := range movies {
+		ids[i] = movies[i].MovieID.String()
+		index[movies[i].MovieID] = i
+		movies[i].Genres = []models.Genre{}
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT mg.movie_id, g.genre_id, g.name
+		FROM movie_genre mg
+		JOIN genre g ON g.genre_id = mg.genre_id
+		WHERE mg.movie_id = ANY($1::uuid[])
+		ORDER BY g.name
+	`, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movieID uuid.UUID
+		var genre models.Genre
+		if err := rows.Scan(&movieID, &genre.GenreID, &genre.Name); err != nil {
+			return err
+		}
+		i := index[movieID]
+		movies[i].Genres = append(movies[i].Genres, genre)
+	}
+	return rows.Err()
+}
+
+// GetGenres grąžina visus žanrus abėcėlės tvarka
+func (r *MovieRepository) GetGenres(ctx context.Context) ([]models.Genre, error) {
+	rows, err := r.pool.Query(ctx, `SELECT genre_id, name FROM genre ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var genres []models.Genre
+	for rows.Next() {
+		var genre models.Genre
+		if err := rows.Scan(&genre.GenreID, &genre.Name); err != nil {
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+	return genres, rows.Err()
+}
+
+// EnsureGenres grąžina žanrus pagal pavadinimus, trūkstamus sukurdama
+// (TMDB importui - TMDB gali pridėti žanrą, kurio nėra mūsų sąraše)
+func (r *MovieRepository) EnsureGenres(ctx context.Context, names []string) ([]models.Genre, error) {
+	if len(names) == 0 {
+		return []models.Genre{}, nil
+	}
+
+	if _, err := r.pool.Exec(ctx, `
+		INSERT INTO genre (name)
+		SELECT unnest($1::text[])
+		ON CONFLICT (name) DO NOTHING
+	`, names); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT genre_id, name FROM genre WHERE name = ANY($1::text[]) ORDER BY name`, names)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	genres := []models.Genre{}
+	for rows.Next() {
+		var genre models.Genre
+		if err := rows.Scan(&genre.GenreID, &genre.Name); err != nil {
+			return nil, err
+		}
+		genres = append(genres, genre)
+	}
+	return genres, rows.Err()
+}
+
+// setMovieGenres pakeičia filmo žanrus nurodytais toje pačioje transakcijoje kaip filmo įrašas
+func setMovieGenres(ctx context.Context, tx pgx.Tx, movieID uuid.UUID, genres []models.Genre) error {
+	ids := make([]string, len(genres))
+	for i, genre := range genres {
+		ids[i] = genre.GenreID.String()
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM movie_genre WHERE movie_id = $1`, movieID); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `
+		INSERT INTO movie_genre (movie_id, genre_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT (movie_id, genre_id) DO NOTHING
+	`, movieID, ids)
+	return err
 }
 
+// CreateMovie sukuria filmą kartu su jo žanrais (movie.Genres) vienoje transakcijoje
 func (r *MovieRepository) CreateMovie(ctx context.Context, movie *models.Movie) error {
 	query := `
         INSERT INTO movie (imdb_id, title, overview, release_date, poster_path, backdrop_path,
@@ -86,7 +520,13 @@ func (r *MovieRepository) CreateMovie(ctx context.Context, movie *models.Movie)
 		imdbID = nil
 	}
 
-	return r.pool.QueryRow(ctx, query,
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, query,
 		imdbID,             // $1 - IMDB ID arba NULL
 		movie.Title,        // $2
 		movie.Overview,     // $3
@@ -99,9 +539,17 @@ func (r *MovieRepository) CreateMovie(ctx context.Context, movie *models.Movie)
 		movie.Runtime,      // $10
 		movie.Status,       // $11
 	).Scan(&movie.MovieID, &movie.CreatedAt)
+	if err != nil {
+		return duplicateImdbID(err)
+	}
+
+	if err := setMovieGenres(ctx, tx, movie.MovieID, movie.Genres); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
-// UpdateMovie atnaujina filmą
+// UpdateMovie atnaujina formos laukus ir žanrus (movie.Genres) vienoje transakcijoje
 func (r *MovieRepository) UpdateMovie(ctx context.Context, movieID uuid.UUID, movie *models.Movie) error {
 	query := `
         UPDATE movie
@@ -110,7 +558,13 @@ func (r *MovieRepository) UpdateMovie(ctx context.Context, movieID uuid.UUID, mo
         WHERE movie_id = $1
     `
 
-	_, err := r.pool.Exec(ctx, query,
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, query,
 		movieID,
 		movie.Title,
 		movie.Overview,
@@ -119,8 +573,52 @@ func (r *MovieRepository) UpdateMovie(ctx context.Context, movieID uuid.UUID, mo
 		movie.Runtime,
 		movie.Status,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if err := setMovieGenres(ctx, tx, movieID, movie.Genres); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ReplaceMovie pakeičia visus redaguojamus filmo laukus ir žanrus (movie.Genres)
+// vienoje transakcijoje (API PUT/PATCH). Jei filmo nėra, grąžina pgx.ErrNoRows.
+func (r *MovieRepository) ReplaceMovie(ctx context.Context, movieID uuid.UUID, movie *models.Movie) error {
+	query := `
+        UPDATE movie
+        SET imdb_id = $2, title = $3, overview = $4, release_date = $5, poster_path = $6,
+            backdrop_path = $7, vote_average = $8, vote_count = $9, popularity = $10,
+            runtime = $11, status = $12
+        WHERE movie_id = $1
+        RETURNING movie_id, created_at
+    `
+
+	var imdbID interface{}
+	if movie.ImdbID != nil && *movie.ImdbID != "" {
+		imdbID = *movie.ImdbID
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, query,
+		movieID, imdbID, movie.Title, movie.Overview, movie.ReleaseDate, movie.PosterPath,
+		movie.BackdropPath, movie.VoteAverage, movie.VoteCount, movie.Popularity,
+		movie.Runtime, movie.Status,
+	).Scan(&movie.MovieID, &movie.CreatedAt)
+	if err != nil {
+		return duplicateImdbID(err)
+	}
+
+	if err := setMovieGenres(ctx, tx, movieID, movie.Genres); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 // DeleteMovie ištrina filmą
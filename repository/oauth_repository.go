@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"battleNet/models"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OAuthRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewOAuthRepository(pool *pgxpool.Pool) *OAuthRepository {
+	return &OAuthRepository{pool: pool}
+}
+
+// GetOAuthByProvider finds the identity linked to a provider account
+func (r *OAuthRepository) GetOAuthByProvider(ctx context.Context, provider, providerID string) (*models.OAuth, error) {
+	query := `
+		SELECT oauth_provider_id, user_id, provider, provider_id, COALESCE(provider_email, ''),
+		       COALESCE(access_token, ''), COALESCE(refresh_token, ''), created_at
+		FROM oauth
+		WHERE provider = $1 AND provider_id = $2
+	`
+
+	var oauth models.OAuth
+	err := r.pool.QueryRow(ctx, query, provider, providerID).Scan(
+		&oauth.OAuthProviderID, &oauth.UserID, &oauth.Provider, &oauth.ProviderID,
+		&oauth.ProviderEmail, &oauth.AccessToken, &oauth.RefreshToken, &oauth.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth, nil
+}
+
+// CreateOAuth links a provider account to a user
+func (r *OAuthRepository) CreateOAuth(ctx context.Context, oauth *models.OAuth) error {
+	query := `
+		INSERT INTO oauth (user_id, provider, provider_id, provider_email, access_token, refresh_token)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''))
+		RETURNING oauth_provider_id, created_at
+	`
+
+	return r.pool.QueryRow(ctx, query,
+		oauth.UserID, oauth.Provider, oauth.ProviderID, oauth.ProviderEmail,
+		oauth.AccessToken, oauth.RefreshToken,
+	).Scan(&oauth.OAuthProviderID, &oauth.CreatedAt)
+}
+
+// UpdateOAuthTokens stores the latest tokens returned by the provider
+func (r *OAuthRepository) UpdateOAuthTokens(ctx context.Context, oauthProviderID uuid.UUID, accessToken, refreshToken string) error {
+	query := `
+		UPDATE oauth
+		SET access_token = NULLIF($2, ''),
+		    refresh_token = COALESCE(NULLIF($3, ''), refresh_token)
+		WHERE oauth_provider_id = $1
+	`
+	_, err := r.pool.Exec(ctx, query, oauthProviderID, accessToken, refreshToken)
+	return err
+}
+
+// GetUserOAuths returns all identities linked to a user
+func (r *OAuthRepository) GetUserOAuths(ctx context.Context, userID uuid.UUID) ([]models.OAuth, error) {
+	query := `
+		SELECT oauth_provider_id, user_id, provider, provider_id, COALESCE(provider_email, ''), created_at
+		FROM oauth
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var identities []models.OAuth
+	for rows.Next() {
+		var oauth models.OAuth
+		err := rows.Scan(
+			&oauth.OAuthProviderID, &oauth.UserID, &oauth.Provider, &oauth.ProviderID,
+			&oauth.ProviderEmail, &oauth.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, oauth)
+	}
+
+	return identities, rows.Err()
+}
+
+// DeleteOAuth unlinks an identity; the user ID check keeps users from removing other users' links
+func (r *OAuthRepository) DeleteOAuth(ctx context.Context, userID, oauthProviderID uuid.UUID) error {
+	query := `DELETE FROM oauth WHERE oauth_provider_id = $1 AND user_id = $2`
+	_, err := r.pool.Exec(ctx, query, oauthProviderID, userID)
+	return err
+}
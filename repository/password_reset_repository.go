@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PasswordResetRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPasswordResetRepository(pool *pgxpool.Pool) *PasswordResetRepository {
+	return &PasswordResetRepository{pool: pool}
+}
+
+// CreateToken stores a new reset token hash and invalidates the user's older unused tokens
+func (r *PasswordResetRepository) CreateToken(ctx context.Context, userID uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `UPDATE password_reset_token SET used_at = NOW() WHERE user_id = $1 AND used_at IS NULL`, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO password_reset_token (user_id, token_hash, expires_at)
+		VALUES ($1, $2, $3)
+	`, userID, tokenHash, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// IsTokenValid checks that a token exists, is unused and not expired
+func (r *PasswordResetRepository) IsTokenValid(ctx context.Context, tokenHash string) (bool, error) {
+	query := `
+		SELECT EXISTS(
+			SELECT 1 FROM password_reset_token
+			WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		)
+	`
+
+	var valid bool
+	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(&valid)
+	return valid, err
+}
+
+// ResetPassword consumes the token and sets the new password in one transaction.
+// Returns pgx.ErrNoRows when the token is unknown, used or expired.
+func (r *PasswordResetRepository) ResetPassword(ctx context.Context, tokenHash, newPasswordHash string) (uuid.UUID, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var userID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		UPDATE password_reset_token
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`, tokenHash).Scan(&userID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE "user" SET password_hash = $2, updated_at = NOW()
+		WHERE user_id = $1 AND is_active = true
+	`, userID, newPasswordHash)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return uuid.Nil, pgx.ErrNoRows
+	}
+
+	return userID, tx.Commit(ctx)
+}
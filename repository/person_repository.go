@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+
+	"battleNet/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PersonRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPersonRepository(pool *pgxpool.Pool) *PersonRepository {
+	return &PersonRepository{pool: pool}
+}
+
+// SaveMovieCredits pakeičia filmo aktorius ir komandą. Asmenys sujungiami
+// pagal TMDB ID (vardas ir nuotrauka atnaujinami), todėl tas pats aktorius
+// skirtinguose filmuose yra vienas person įrašas. Kredituose Person.TmdbID privalomas.
+func (r *PersonRepository) SaveMovieCredits(ctx context.Context, movieID uuid.UUID, credits models.MovieCredits) error {
+	// Vienas asmuo gali būti ir aktorius, ir režisierius - upsert'ui kiekvienas tik kartą
+	var tmdbIDs []int32
+	var names, profiles []string
+	seen := make(map[int]bool)
+	addPerson := func(p models.Person) {
+		if p.TmdbID == nil || seen[*p.TmdbID] {
+			return
+		}
+		seen[*p.TmdbID] = true
+		tmdbIDs = append(tmdbIDs, int32(*p.TmdbID))
+		names = append(names, p.Name)
+		profile := ""
+		if p.ProfilePath != nil {
+			profile = *p.ProfilePath
+		}
+		profiles = append(profiles, profile)
+	}
+	for _, member := range credits.Cast {
+		addPerson(member.Person)
+	}
+	for _, member := range credits.Crew {
+		addPerson(member.Person)
+	}
+
+	var castIDs, castOrders []int32
+	var characters []string
+	for _, member := range credits.Cast {
+		if member.TmdbID != nil {
+			castIDs = append(castIDs, int32(*member.TmdbID))
+			characters = append(characters, member.Character)
+			castOrders = append(castOrders, int32(member.Order))
+		}
+	}
+
+	var crewIDs []int32
+	var departments, jobs []string
+	for _, member := range credits.Crew {
+		if member.TmdbID != nil {
+			crewIDs = append(crewIDs, int32(*member.TmdbID))
+			departments = append(departments, member.Department)
+			jobs = append(jobs, member.Job)
+		}
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO person (tmdb_id, name, profile_path)
+		SELECT p.tmdb_id, p.name, NULLIF(p.profile_path, '')
+		FROM unnest($1::int[], $2::text[], $3::text[]) AS p(tmdb_id, name, profile_path)
+		ON CONFLICT (tmdb_id) DO UPDATE
+		SET name = EXCLUDED.name,
+		    profile_path = COALESCE(EXCLUDED.profile_path, person.profile_path)
+	`, tmdbIDs, names, profiles); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM movie_cast WHERE movie_id = $1`, movieID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM movie_crew WHERE movie_id = $1`, movieID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO movie_cast (movie_id, person_id, character_name, billing_order)
+		SELECT $1, p.person_id, NULLIF(c.character_name, ''), c.billing_order
+		FROM unnest($2::int[], $3::text[], $4::int[]) AS c(tmdb_id, character_name, billing_order)
+		JOIN person p ON p.tmdb_id = c.tmdb_id
+	`, movieID, castIDs, characters, castOrders); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO movie_crew (movie_id, person_id, department, job)
+		SELECT $1, p.person_id, c.department, c.job
+		FROM unnest($2::int[], $3::text[], $4::text[]) AS c(tmdb_id, department, job)
+		JOIN person p ON p.tmdb_id = c.tmdb_id
+		ON CONFLICT (movie_id, person_id, job) DO NOTHING
+	`, movieID, crewIDs, departments, jobs); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetMovieCredits grąžina filmo aktorius (pagal billing order) ir komandą
+// (pagal skyrių ir pareigas). Sąrašai niekada nėra nil.
+func (r *PersonRepository) GetMovieCredits(ctx context.Context, movieID uuid.UUID) (models.MovieCredits, error) {
+	credits := models.MovieCredits{Cast: []models.CastCredit{}, Crew: []models.CrewCredit{}}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT p.person_id, p.tmdb_id, p.name, p.profile_path,
+		       COALESCE(c.character_name, ''), c.billing_order
+		FROM movie_cast c
+		JOIN person p ON p.person_id = c.person_id
+		WHERE c.movie_id = $1
+		ORDER BY c.billing_order, p.name
+	`, movieID)
+	if err != nil {
+		return credits, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var member models.CastCredit
+		if err := rows.Scan(
+			&member.PersonID, &member.TmdbID, &member.Name, &member.ProfilePath,
+			&member.Character, &member.Order,
+		); err != nil {
+			return credits, err
+		}
+		credits.Cast = append(credits.Cast, member)
+	}
+	if err := rows.Err(); err != nil {
+		return credits, err
+	}
+
+	rows, err = r.pool.Query(ctx, `
+		SELECT p.person_id, p.tmdb_id, p.name, p.profile_path, c.department, c.job
+		FROM movie_crew c
+		JOIN person p ON p.person_id = c.person_id
+		WHERE c.movie_id = $1
+		ORDER BY c.department, c.job, p.name
+	`, movieID)
+	if err != nil {
+		return credits, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var member models.CrewCredit
+		if err := rows.Scan(
+			&member.PersonID, &member.TmdbID, &member.Name, &member.ProfilePath,
+			&member.Department, &member.Job,
+		); err != nil {
+			return credits, err
+		}
+		credits.Crew = append(credits.Crew, member)
+	}
+	return credits, rows.Err()
+}
+
+// GetPerson grąžina asmenį su TMDB informacija. Returns pgx.ErrNoRows when
+// the person does not exist.
+func (r *PersonRepository) GetPerson(ctx context.Context, personID uuid.UUID) (*models.PersonDetails, error) {
+	var p models.PersonDetails
+	err := r.pool.QueryRow(ctx, `
+		SELECT person_id, tmdb_id, name, profile_path, biography, birthday, deathday,
+		       place_of_birth, known_for_department, details_fetched_at
+		FROM person
+		WHERE person_id = $1
+	`, personID).Scan(
+		&p.PersonID, &p.TmdbID, &p.Name, &p.ProfilePath, &p.Biography, &p.Birthday, &p.Deathday,
+		&p.PlaceOfBirth, &p.KnownForDepartment, &p.DetailsFetchedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// UpdatePersonDetails įrašo iš TMDB gautą informaciją ir pažymi details_fetched_at
+func (r *PersonRepository) UpdatePersonDetails(ctx context.Context, p *models.PersonDetails) error {
+	return r.pool.QueryRow(ctx, `
+		UPDATE person
+		SET name = $2, profile_path = COALESCE($3, profile_path), biography = $4, birthday = $5,
+		    deathday = $6, place_of_birth = $7, known_for_department = $8, details_fetched_at = NOW()
+		WHERE person_id = $1
+		RETURNING profile_path, details_fetched_at
+	`, p.PersonID, p.Name, p.ProfilePath, p.Biography, p.Birthday, p.Deathday,
+		p.PlaceOfBirth, p.KnownForDepartment,
+	).Scan(&p.ProfilePath, &p.DetailsFetchedAt)
+}
+
+// GetFilmography grąžina asmens vaidmenis ir pareigas mūsų kataloge,
+// naujausius filmus pirmus (be datos - gale), su viešų recenzijų vidurkiu
+func (r *PersonRepository) GetFilmography(ctx context.Context, personID uuid.UUID) (models.Filmography, error) {
+	filmography := models.Filmography{Acting: []models.FilmographyCredit{}, Crew: []models.FilmographyJob{}}
+
+	// Keli vaidmenys tame pačiame filme sujungiami į vieną įrašą
+	rows, err := r.pool.Query(ctx, `
+		SELECT m.movie_id, m.title, m.release_date, m.poster_path,
+		       COALESCE(string_agg(DISTINCT c.character_name, ' / '), ''),
+		       rs.average_rating, rs.review_count
+		FROM movie_cast c
+		JOIN movie m ON m.movie_id = c.movie_id
+		LEFT JOIN LATERAL (
+			SELECT AVG(rating)::float8 AS average_rating, COUNT(*)::int AS review_count
+			FROM review
+			WHERE movie_id = m.movie_id AND is_public = true
+		) rs ON true
+		WHERE c.person_id = $1
+		GROUP BY m.movie_id, rs.average_rating, rs.review_count
+		ORDER BY m.release_date DESC NULLS LAST, m.title
+	`, personID)
+	if err != nil {
+		return filmography, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var credit models.FilmographyCredit
+		if err := rows.Scan(
+			&credit.MovieID, &credit.Title, &credit.ReleaseDate, &credit.PosterPath,
+			&credit.Character, &credit.AverageRating, &credit.ReviewCount,
+		); err != nil {
+			return filmography, err
+		}
+		filmography.Acting = append(filmography.Acting, credit)
+	}
+	if err := rows.Err(); err != nil {
+		return filmography, err
+	}
+
+	rows, err = r.pool.Query(ctx, `
+		SELECT c.department, c.job, m.movie_id, m.title, m.release_date, m.poster_path,
+		       rs.average_rating, rs.review_count
+		FROM movie_crew c
+		JOIN movie m ON m.movie_id = c.movie_id
+		LEFT JOIN LATERAL (
+			SELECT AVG(rating)::float8 AS average_rating, COUNT(*)::int AS review_count
+			FROM review
+			WHERE movie_id = m.movie_id AND is_public = true
+		) rs ON true
+		WHERE c.person_id = $1
+		ORDER BY c.department, c.job, m.release_date DESC NULLS LAST, m.title
+	`, personID)
+	if err != nil {
+		return filmography, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var department, job string
+		var credit models.FilmographyCredit
+		if err := rows.Scan(
+			&department, &job, &credit.MovieID, &credit.Title, &credit.ReleaseDate, &credit.PosterPath,
+			&credit.AverageRating, &credit.ReviewCount,
+		); err != nil {
+			return filmography, err
+		}
+		// Eilutės surikiuotos pagal pareigas - nauja grupė, kai jos pasikeičia
+		n := len(filmography.Crew)
+		if n == 0 || filmography.Crew[n-1].Department != department || filmography.Crew[n-1].Job != job {
+			filmography.Crew = append(filmography.Crew, models.FilmographyJob{Department: department, Job: job})
+			n++
+		}
+		filmography.Crew[n-1].Movies = append(filmography.Crew[n-1].Movies, credit)
+	}
+	return filmography, rows.Err()
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"battleNet/models"
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PersonalAccessTokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPersonalAccessTokenRepository(pool *pgxpool.Pool) *PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{pool: pool}
+}
+
+// CreateToken išsaugo naują token'ą (tik jo hash)
+func (r *PersonalAccessTokenRepository) CreateToken(ctx context.Context, token *models.PersonalAccessToken, tokenHash string) error {
+	query := `
+		INSERT INTO personal_access_token (user_id, name, token_hash, token_prefix, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING token_id, created_at
+	`
+
+	return r.pool.QueryRow(ctx, query,
+		token.UserID, token.Name, tokenHash, token.TokenPrefix, token.Scopes, token.ExpiresAt,
+	).Scan(&token.TokenID, &token.CreatedAt)
+}
+
+// GetUserTokens grąžina visus vartotojo token'us, naujausi pirmi
+func (r *PersonalAccessTokenRepository) GetUserTokens(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, error) {
+	query := `
+		SELECT token_id, user_id, name, token_prefix, scopes, expires_at, last_used_at, created_at
+		FROM personal_access_token
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []models.PersonalAccessToken
+	for rows.Next() {
+		var token models.PersonalAccessToken
+		err := rows.Scan(
+			&token.TokenID, &token.UserID, &token.Name, &token.TokenPrefix, &token.Scopes,
+			&token.ExpiresAt, &token.LastUsedAt, &token.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// DeleteToken atšaukia (ištrina) vartotojo token'ą
+func (r *PersonalAccessTokenRepository) DeleteToken(ctx context.Context, userID, tokenID uuid.UUID) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM personal_access_token WHERE token_id = $1 AND user_id = $2`, tokenID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Authenticate suranda galiojantį token'ą, atnaujina last_used_at ir grąžina
+// vartotoją bei token'o scope'us. Returns pgx.ErrNoRows for unknown or expired
+// tokens and inactive users.
+func (r *PersonalAccessTokenRepository) Authenticate(ctx context.Context, tokenHash string) (*models.User, []string, error) {
+	query := `
+		UPDATE personal_access_token t
+		SET last_used_at = NOW()
+		FROM "user" u
+		WHERE t.token_hash = $1
+		  AND (t.expires_at IS NULL OR t.expires_at > NOW())
+		  AND u.user_id = t.user_id AND u.is_active = true
+		RETURNING u.user_id, u.email, u.first_name, u.last_name, u.username, u.role,
+		          u.is_active, u.email_verified, t.scopes
+	`
+
+	var user models.User
+	var scopes []string
+	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(
+		&user.UserID, &user.Email, &user.FirstName, &user.LastName, &user.Username, &user.Role,
+		&user.IsActive, &user.EmailVerified, &scopes,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &user, scopes, nil
+}
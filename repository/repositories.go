@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Repositories - visos handler'ių saugyklos, sukurtos vieną kartą tam pačiam pool
+type Repositories struct {
+	Users                *UserRepository
+	Movies               *MovieRepository
+	Reviews              *ReviewRepository
+	Watchlist            *WatchlistRepository
+	OAuth                *OAuthRepository
+	PasswordResets       *PasswordResetRepository
+	APISessions          *APISessionRepository
+	PersonalAccessTokens *PersonalAccessTokenRepository
+	TwoFactor            *TwoFactorRepository
+	LoginAttempts        *LoginAttemptRepository
+	Audit                *AuditRepository
+	Suspensions          *SuspensionRepository
+	People               *PersonRepository
+}
+
+// NewRepositories sukuria visas saugyklas. suspensionExpiry - kas kiek
+// nuimamos pasibaigusios suspensijos (0 - background darbas nepaleidžiamas).
+func NewRepositories(pool *pgxpool.Pool, suspensionExpiry time.Duration) *Repositories {
+	return &Repositories{
+		Users:                NewUserRepository(pool),
+		Movies:               NewMovieRepository(pool),
+		Reviews:              NewReviewRepository(pool),
+		Watchlist:            NewWatchlistRepository(pool),
+		OAuth:                NewOAuthRepository(pool),
+		PasswordResets:       NewPasswordResetRepository(pool),
+		APISessions:          NewAPISessionRepository(pool),
+		PersonalAccessTokens: NewPersonalAccessTokenRepository(pool),
+		TwoFactor:            NewTwoFactorRepository(pool),
+		LoginAttempts:        NewLoginAttemptRepository(pool),
+		Audit:                NewAuditRepository(pool),
+		Suspensions:          NewSuspensionRepository(pool, suspensionExpiry),
+		People:               NewPersonRepository(pool),
+	}
+}
+
+// Close sustabdo background darbus (kviesti prieš uždarant DB pool)
+func (r *Repositories) Close() {
+	r.Suspensions.StopExpirer()
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+
+	"battleNet/permissions"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RoleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRoleRepository(pool *pgxpool.Pool) *RoleRepository {
+	return &RoleRepository{pool: pool}
+}
+
+// GetRoles grąžina visas roles su tiesiogiai priskirtais (nepaveldėtais) leidimais
+func (r *RoleRepository) GetRoles(ctx context.Context) ([]permissions.Role, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT r.name, COALESCE(r.parent_role, ''),
+		       COALESCE(array_agg(rp.permission_name ORDER BY rp.permission_name) FILTER (WHERE rp.permission_name IS NOT NULL), '{}')
+		FROM role r
+		LEFT JOIN role_permission rp ON rp.role_name = r.name
+		GROUP BY r.name, r.parent_role
+		ORDER BY r.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var roles []permissions.Role
+	for rows.Next() {
+		var role permissions.Role
+		if err := rows.Scan(&role.Name, &role.Parent, &role.Permissions); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	return roles, rows.Err()
+}
+
+// LoadPolicy nuskaito roles iš DB ir išsprendžia paveldėjimą
+func (r *RoleRepository) LoadPolicy(ctx context.Context) (*permissions.Policy, error) {
+	roles, err := r.GetRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return permissions.NewPolicy(roles)
+}
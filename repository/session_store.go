@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"battleNet/models"
+	"context"
+	"errors"
+	"log"
+	"net/netip"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lastUsedResolution - kas kiek FindCtx atnaujina last_used_at (ne kiekvienos užklausos metu)
+const lastUsedResolution = time.Minute
+
+// revokedRetention - kiek laiko laikomos ištrintos (expires_at = NOW()) sesijos.
+// Kol eilutė yra, CommitCtx jos neatkuria, net jei užklausa ją įkėlė prieš ištrynimą.
+const revokedRetention = time.Hour
+
+// SessionStore - scs.Store implementacija ant user_session lentelės, kad
+// sesijos išliktų po restarto ir būtų bendros keliems serverio instance'ams.
+// Sesijos trinamos nustatant expires_at = NOW(), o eilutes vėliau išvalo
+// DeleteExpired - taip CommitCtx gali atskirti ištrintą sesiją nuo naujos.
+type SessionStore struct {
+	pool        *pgxpool.Pool
+	codec       scs.Codec
+	stopCleanup chan struct{}
+}
+
+// NewSessionStore creates the store and starts a background sweeper that
+// deletes expired sessions every cleanupInterval. A zero interval disables it.
+func NewSessionStore(pool *pgxpool.Pool, cleanupInterval time.Duration) *SessionStore {
+	s := &SessionStore{pool: pool, codec: scs.GobCodec{}}
+	if cleanupInterval > 0 {
+		s.stopCleanup = make(chan struct{})
+		go s.startCleanup(cleanupInterval, s.stopCleanup)
+	}
+	return s
+}
+
+// FindCtx grąžina sesijos duomenis. last_used_at atnaujinamas ne dažniau nei
+// kas lastUsedResolution, kad kiekviena užklausa nerašytų į DB.
+func (s *SessionStore) FindCtx(ctx context.Context, token string) ([]byte, bool, error) {
+	query := `
+		SELECT data, last_used_at FROM user_session
+		WHERE session_token = $1 AND expires_at > NOW()
+	`
+
+	var data []byte
+	var lastUsedAt time.Time
+	err := s.pool.QueryRow(ctx, query, token).Scan(&data, &lastUsedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if time.Since(lastUsedAt) >= lastUsedResolution {
+		_, err := s.pool.Exec(ctx, `
+			UPDATE user_session SET last_used_at = NOW()
+			WHERE session_token = $1 AND expires_at > NOW()
+		`, token)
+		if err != nil {
+			log.Printf("Failed to update session last_used_at: %v", err)
+		}
+	}
+	return data, true, nil
+}
+
+// CommitCtx išsaugo sesiją. Vartotojo ID, įrenginys ir IP ištraukiami iš
+// sesijos reikšmių, kad juos būtų galima naudoti užklausose.
+func (s *SessionStore) CommitCtx(ctx context.Context, token string, b []byte, expiry time.Time) error {
+	var userID *uuid.UUID
+	var deviceInfo, ipAddress *string
+
+	if _, values, err := s.codec.Decode(b); err == nil {
+		if id, err := uuid.Parse(stringValue(values, "userID")); err == nil {
+			userID = &id
+		}
+		if v := stringValue(values, "device_info"); v != "" {
+			deviceInfo = &v
+		}
+		if addr, err := netip.ParseAddr(stringValue(values, "ip_address")); err == nil {
+			ip := addr.String()
+			ipAddress = &ip
+		}
+	}
+
+	// Galiojanti sesija atnaujinama
+	update := `
+		UPDATE user_session
+		SET data = $2, expires_at = $3, user_id = $4, device_info = $5, ip_address = $6::inet, last_used_at = NOW()
+		WHERE session_token = $1 AND expires_at > NOW()
+	`
+	tag, err := s.pool.Exec(ctx, update, token, b, expiry, userID, deviceInfo, ipAddress)
+	if err != nil || tag.RowsAffected() > 0 {
+		return err
+	}
+
+	// Įrašoma tik nauja sesija. Jei token'as jau yra, sesija buvo ištrinta
+	// (pvz. atjungta iš kito įrenginio) kol ši užklausa vyko - jos neatkuriam.
+	insert := `
+		INSERT INTO user_session (session_token, data, expires_at, user_id, device_info, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6::inet)
+		ON CONFLICT (session_token) DO NOTHING
+	`
+	_, err = s.pool.Exec(ctx, insert, token, b, expiry, userID, deviceInfo, ipAddress)
+	return err
+}
+
+func (s *SessionStore) DeleteCtx(ctx context.Context, token string) error {
+	_, err := s.pool.Exec(ctx, `UPDATE user_session SET expires_at = NOW() WHERE session_token = $1 AND expires_at > NOW()`, token)
+	return err
+}
+
+// AllCtx grąžina visas galiojančias sesijas (naudoja SessionManager.Iterate)
+func (s *SessionStore) AllCtx(ctx context.Context) (map[string][]byte, error) {
+	rows, err := s.pool.Query(ctx, `SELECT session_token, data FROM user_session WHERE expires_at > NOW()`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := make(map[string][]byte)
+	for rows.Next() {
+		var token string
+		var data []byte
+		if err := rows.Scan(&token, &data); err != nil {
+			return nil, err
+		}
+		sessions[token] = data
+	}
+
+	return sessions, rows.Err()
+}
+
+func (s *SessionStore) Find(token string) ([]byte, bool, error) {
+	return s.FindCtx(context.Background(), token)
+}
+
+func (s *SessionStore) Commit(token string, b []byte, expiry time.Time) error {
+	return s.CommitCtx(context.Background(), token, b, expiry)
+}
+
+func (s *SessionStore) Delete(token string) error {
+	return s.DeleteCtx(context.Background(), token)
+}
+
+func (s *SessionStore) All() (map[string][]byte, error) {
+	return s.AllCtx(context.Background())
+}
+
+// ListByUser grąžina vartotojo aktyvias sesijas, naujausiai naudotas pirmas.
+// currentToken pažymi sesiją, iš kurios atėjo užklausa.
+func (s *SessionStore) ListByUser(ctx context.Context, userID uuid.UUID, currentToken string) ([]models.UserSession, error) {
+	query := `
+		SELECT session_id, session_token, COALESCE(device_info, ''), COALESCE(host(ip_address), ''),
+		       created_at, last_used_at, expires_at
+		FROM user_session
+		WHERE user_id = $1 AND expires_at > NOW()
+		ORDER BY last_used_at DESC
+	`
+
+	rows, err := s.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.UserSession
+	for rows.Next() {
+		var session models.UserSession
+		var token string
+		err := rows.Scan(
+			&session.SessionID, &token, &session.DeviceInfo, &session.IPAddress,
+			&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		session.Current = token == currentToken
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// DeleteByID ištrina vieną vartotojo sesiją. Grąžina false, jei tokios sesijos
+// nėra arba ji priklauso kitam vartotojui.
+func (s *SessionStore) DeleteByID(ctx context.Context, userID, sessionID uuid.UUID) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE user_session SET expires_at = NOW()
+		WHERE session_id = $1 AND user_id = $2 AND expires_at > NOW()
+	`, sessionID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteByUser ištrina visas vartotojo sesijas, išskyrus exceptToken (jei nurodytas)
+func (s *SessionStore) DeleteByUser(ctx context.Context, userID uuid.UUID, exceptToken string) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE user_session SET expires_at = NOW()
+		WHERE user_id = $1 AND session_token <> $2 AND expires_at > NOW()
+	`, userID, exceptToken)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteExpired ištrina sesijas, pasibaigusias ar ištrintas prieš daugiau nei revokedRetention
+func (s *SessionStore) DeleteExpired(ctx context.Context) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM user_session WHERE expires_at <= $1`, time.Now().Add(-revokedRetention))
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// StopCleanup sustabdo background sweeper'į (kviesti prieš uždarant DB pool)
+func (s *SessionStore) StopCleanup() {
+	if s.stopCleanup != nil {
+		close(s.stopCleanup)
+		s.stopCleanup = nil
+	}
+}
+
+func (s *SessionStore) startCleanup(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			deleted, err := s.DeleteExpired(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("Session cleanup failed: %v", err)
+			} else if deleted > 0 {
+				log.Printf("🧹 Removed %d expired sessions", deleted)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func stringValue(values map[string]interface{}, key string) string {
+	v, _ := values[key].(string)
+	return v
+}
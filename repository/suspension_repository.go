@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"battleNet/models"
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SuspensionRepository struct {
+	pool        *pgxpool.Pool
+	stopExpirer chan struct{}
+}
+
+// NewSuspensionRepository sukuria repository ir, jei expiryInterval > 0,
+// paleidžia background'e pasibaigusių suspensijų nuėmimą
+func NewSuspensionRepository(pool *pgxpool.Pool, expiryInterval time.Duration) *SuspensionRepository {
+	r := &SuspensionRepository{pool: pool}
+	if expiryInterval > 0 {
+		r.stopExpirer = make(chan struct{})
+		go r.startExpirer(expiryInterval, r.stopExpirer)
+	}
+	return r
+}
+
+// suspendedUserQuery - vartotojas su aktyvia (nenuimta) suspensija
+const suspendedUserQuery = `
+	SELECT u.user_id, u.email, u.password_hash, u.first_name, u.last_name, u.username,
+	       u.role, u.is_active, u.avatar_url, u.email_verified, u.created_at, u.updated_at, u.last_login_at,
+	       s.suspension_id, s.reason, s.suspended_by, COALESCE(m.email, ''), s.suspended_at, s.expires_at
+	FROM user_suspension s
+	JOIN "user" u ON u.user_id = s.user_id
+	LEFT JOIN "user" m ON m.user_id = s.suspended_by
+	WHERE s.lifted_at IS NULL`
+
+func scanSuspendedUser(row pgx.Row) (*models.SuspendedUser, error) {
+	var su models.SuspendedUser
+	err := row.Scan(
+		&su.User.UserID, &su.User.Email, &su.User.PasswordHash, &su.User.FirstName, &su.User.LastName,
+		&su.User.Username, &su.User.Role, &su.User.IsActive, &su.User.AvatarURL, &su.User.EmailVerified,
+		&su.User.CreatedAt, &su.User.UpdatedAt, &su.User.LastLoginAt,
+		&su.Suspension.SuspensionID, &su.Suspension.Reason, &su.Suspension.SuspendedBy,
+		&su.Suspension.SuspendedByEmail, &su.Suspension.SuspendedAt, &su.Suspension.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	su.Suspension.UserID = su.User.UserID
+	return &su, nil
+}
+
+// Suspend suspenduoja vartotoją (ankstesnė aktyvi suspensija pakeičiama nauja)
+func (r *SuspensionRepository) Suspend(ctx context.Context, userID uuid.UUID, reason string, suspendedBy uuid.UUID, expiresAt *time.Time) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE user_suspension SET lifted_at = NOW(), lifted_by = $2
+		WHERE user_id = $1 AND lifted_at IS NULL
+	`, userID, suspendedBy); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO user_suspension (user_id, reason, suspended_by, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, userID, reason, suspendedBy, expiresAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE "user" SET is_active = false, updated_at = NOW() WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Lift nuima aktyvią suspensiją ir aktyvuoja vartotoją. liftedBy nil - nuimta automatiškai.
+func (r *SuspensionRepository) Lift(ctx context.Context, userID uuid.UUID, liftedBy *uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE user_suspension SET lifted_at = NOW(), lifted_by = $2
+		WHERE user_id = $1 AND lifted_at IS NULL
+	`, userID, liftedBy); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE "user" SET is_active = true, updated_at = NOW() WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetSuspendedUser grąžina suspenduotą vartotoją. Returns pgx.ErrNoRows when
+// the user has no active suspension.
+func (r *SuspensionRepository) GetSuspendedUser(ctx context.Context, userID uuid.UUID) (*models.SuspendedUser, error) {
+	return scanSuspendedUser(r.pool.QueryRow(ctx, suspendedUserQuery+` AND s.user_id = $1`, userID))
+}
+
+// GetSuspendedUserByEmail - kaip GetSuspendedUser, bet pagal email (prisijungimui)
+func (r *SuspensionRepository) GetSuspendedUserByEmail(ctx context.Context, email string) (*models.SuspendedUser, error) {
+	return scanSuspendedUser(r.pool.QueryRow(ctx, suspendedUserQuery+` AND u.email = $1`, email))
+}
+
+// GetSuspendedUsers grąžina visus suspenduotus vartotojus nuo naujausios suspensijos
+func (r *SuspensionRepository) GetSuspendedUsers(ctx context.Context) ([]models.SuspendedUser, error) {
+	rows, err := r.pool.Query(ctx, suspendedUserQuery+` ORDER BY s.suspended_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.SuspendedUser
+	for rows.Next() {
+		su, err := scanSuspendedUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		su.User.PasswordHash = ""
+		users = append(users, *su)
+	}
+
+	return users, rows.Err()
+}
+
+// LiftExpired nuima visas pasibaigusias suspensijas. Grąžina aktyvuotų vartotojų skaičių.
+func (r *SuspensionRepository) LiftExpired(ctx context.Context) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `
+		WITH lifted AS (
+			UPDATE user_suspension SET lifted_at = NOW()
+			WHERE lifted_at IS NULL AND expires_at <= NOW()
+			RETURNING user_id
+		)
+		UPDATE "user" SET is_active = true, updated_at = NOW()
+		WHERE user_id IN (SELECT user_id FROM lifted)
+	`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// StopExpirer sustabdo background nuėmimą (kviesti prieš uždarant DB pool)
+func (r *SuspensionRepository) StopExpirer() {
+	if r.stopExpirer != nil {
+		close(r.stopExpirer)
+		r.stopExpirer = nil
+	}
+}
+
+func (r *SuspensionRepository) startExpirer(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			lifted, err := r.LiftExpired(ctx)
+			cancel()
+			if err != nil {
+				log.Printf("Suspension expiry check failed: %v", err)
+			} else if lifted > 0 {
+				log.Printf("🔓 Lifted %d expired suspensions", lifted)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
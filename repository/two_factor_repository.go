@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TwoFactorRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTwoFactorRepository(pool *pgxpool.Pool) *TwoFactorRepository {
+	return &TwoFactorRepository{pool: pool}
+}
+
+// IsEnabled - ar vartotojas turi įjungtą 2FA
+func (r *TwoFactorRepository) IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var enabled bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM user_totp WHERE user_id = $1 AND enabled_at IS NOT NULL)
+	`, userID).Scan(&enabled)
+	return enabled, err
+}
+
+// GetSecret grąžina TOTP secret'ą ir ar 2FA jau įjungtas.
+// Returns pgx.ErrNoRows when enrollment hasn't started.
+func (r *TwoFactorRepository) GetSecret(ctx context.Context, userID uuid.UUID) (secret string, enabled bool, err error) {
+	err = r.pool.QueryRow(ctx, `
+		SELECT secret, enabled_at IS NOT NULL FROM user_totp WHERE user_id = $1
+	`, userID).Scan(&secret, &enabled)
+	return secret, enabled, err
+}
+
+// StartEnrollment išsaugo naują secret'ą nebaigtai registracijai. Jau įjungto
+// 2FA neperrašo - tada grąžina false.
+func (r *TwoFactorRepository) StartEnrollment(ctx context.Context, userID uuid.UUID, secret string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO user_totp (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = NULL, created_at = NOW()
+		WHERE user_totp.enabled_at IS NULL
+	`, userID, secret)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Enable įjungia 2FA ir pakeičia atkūrimo kodus naujais. step - patvirtinimo
+// kodo žingsnis, kad to paties kodo nebūtų galima panaudoti prisijungimui.
+func (r *TwoFactorRepository) Enable(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE user_totp SET enabled_at = NOW(), last_used_step = $2
+		WHERE user_id = $1 AND enabled_at IS NULL
+	`, userID, step)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RegenerateRecoveryCodes pakeičia atkūrimo kodus (seni nebegalioja)
+func (r *TwoFactorRepository) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, recoveryCodeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Disable išjungia 2FA ir ištrina atkūrimo kodus
+func (r *TwoFactorRepository) Disable(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM two_factor_recovery_code WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ClaimStep pažymi kodo žingsnį kaip panaudotą. Grąžina false, jei šis ar
+// vėlesnis kodas jau buvo panaudotas (replay).
+func (r *TwoFactorRepository) ClaimStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE user_totp SET last_used_step = $2
+		WHERE user_id = $1 AND enabled_at IS NOT NULL
+		  AND (last_used_step IS NULL OR last_used_step < $2)
+	`, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// UseRecoveryCode panaudoja atkūrimo kodą vieną kartą
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE two_factor_recovery_code SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// CountRecoveryCodes grąžina nepanaudotų atkūrimo kodų skaičių
+func (r *TwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM two_factor_recovery_code WHERE user_id = $1 AND used_at IS NULL
+	`, userID).Scan(&count)
+	return count, err
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID uuid.UUID, codeHashes []string) error {
+	if len(codeHashes) == 0 {
+		return errors.New("no recovery codes")
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM two_factor_recovery_code WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO two_factor_recovery_code (user_id, code_hash)
+		SELECT $1, unnest($2::text[])
+	`, userID, codeHashes)
+	return err
+}
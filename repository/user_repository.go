@@ -3,6 +3,9 @@ package repository
 import (
 	"battleNet/models"
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -40,17 +43,36 @@ func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*mod
 
 func (r *UserRepository) CreateUser(ctx context.Context, user *models.User) error {
 	query := `
-		INSERT INTO "user" (email, password_hash, first_name, last_name, username, role)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO "user" (email, password_hash, first_name, last_name, username, role, avatar_url, email_verified)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING user_id, created_at, updated_at
 	`
 
 	return r.pool.QueryRow(ctx, query,
 		user.Email, user.PasswordHash, user.FirstName, user.LastName,
-		user.Username, user.Role,
+		user.Username, user.Role, user.AvatarURL, user.EmailVerified,
 	).Scan(&user.UserID, &user.CreatedAt, &user.UpdatedAt)
 }
 
+// UsernameExists checks whether a username is already taken
+func (r *UserRepository) UsernameExists(ctx context.Context, username string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM "user" WHERE username = $1)`
+
+	var exists bool
+	err := r.pool.QueryRow(ctx, query, username).Scan(&exists)
+	return exists, err
+}
+
+// EmailExists checks whether an email is already registered, including
+// suspended accounts (GetUserByEmail skips them, the UNIQUE constraint does not)
+func (r *UserRepository) EmailExists(ctx context.Context, email string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM "user" WHERE email = $1)`
+
+	var exists bool
+	err := r.pool.QueryRow(ctx, query, email).Scan(&exists)
+	return exists, err
+}
+
 func (r *UserRepository) UpdateLastLogin(ctx context.Context, userID uuid.UUID) error {
 	query := `UPDATE "user" SET last_login_at = NOW() WHERE user_id = $1`
 	_, err := r.pool.Exec(ctx, query, userID)
@@ -94,6 +116,37 @@ func (r *UserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, n
 	return err
 }
 
+// MarkEmailVerified patvirtina email, jei jis nepasikeitė nuo token'o išdavimo
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID, email string) (bool, error) {
+	query := `
+		UPDATE "user"
+		SET email_verified = true, updated_at = NOW()
+		WHERE user_id = $1 AND email = $2 AND is_active = true
+	`
+	tag, err := r.pool.Exec(ctx, query, userID, email)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ClaimVerificationSend records a verification email send unless one was sent
+// within the interval. It returns false when the caller should not send.
+func (r *UserRepository) ClaimVerificationSend(ctx context.Context, userID uuid.UUID, interval time.Duration) (bool, error) {
+	query := `
+		UPDATE "user"
+		SET verification_sent_at = NOW()
+		WHERE user_id = $1
+		  AND email_verified = false
+		  AND (verification_sent_at IS NULL OR verification_sent_at < NOW() - make_interval(secs => $2))
+	`
+	tag, err := r.pool.Exec(ctx, query, userID, interval.Seconds())
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
 // Moderator
 func (r *UserRepository) GetAllUsers(ctx context.Context, limit, offset int32) ([]models.User, error) {
 	query := `
@@ -129,6 +182,117 @@ func (r *UserRepository) GetAllUsers(ctx context.Context, limit, offset int32) (
 	return users, nil
 }
 
+// userSortColumns - leidžiami rikiavimo stulpeliai (SQL injekcijos apsauga)
+var userSortColumns = map[string]string{
+	"email":         "email",
+	"username":      "username",
+	"name":          "last_name, first_name",
+	"role":          "role",
+	"created_at":    "created_at",
+	"last_login_at": "last_login_at",
+}
+
+// userFilterWhere sudaro WHERE sąlygą ir argumentus pagal filtrą
+func userFilterWhere(filter models.UserFilter) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if query := strings.TrimSpace(filter.Query); query != "" {
+		args = append(args, "%"+escapeLike(query)+"%")
+		n := len(args)
+		conditions = append(conditions, fmt.Sprintf(
+			"(email ILIKE $%d OR username ILIKE $%d OR first_name ILIKE $%d OR last_name ILIKE $%d OR (first_name || ' ' || last_name) ILIKE $%d)",
+			n, n, n, n, n))
+	}
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		conditions = append(conditions, fmt.Sprintf("role = $%d", len(args)))
+	}
+	if filter.Active != nil {
+		args = append(args, *filter.Active)
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", len(args)))
+	}
+	if filter.EmailVerified != nil {
+		args = append(args, *filter.EmailVerified)
+		conditions = append(conditions, fmt.Sprintf("email_verified = $%d", len(args)))
+	}
+	if filter.LastLoginFrom != nil {
+		args = append(args, *filter.LastLoginFrom)
+		conditions = append(conditions, fmt.Sprintf("last_login_at >= $%d", len(args)))
+	}
+	if filter.LastLoginTo != nil {
+		args = append(args, *filter.LastLoginTo)
+		conditions = append(conditions, fmt.Sprintf("last_login_at < $%d", len(args)))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return "WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// escapeLike - kad vartotojo įvesti % ir _ nebūtų LIKE šablonai
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// SearchUsers grąžina filtro puslapį ir bendrą atitinkančių vartotojų skaičių
+func (r *UserRepository) SearchUsers(ctx context.Context, filter models.UserFilter) ([]models.User, int, error) {
+	where, args := userFilterWhere(filter)
+
+	var total int
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM "user" `+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	orderBy, ok := userSortColumns[filter.Sort]
+	if !ok {
+		orderBy = userSortColumns["created_at"]
+	}
+	direction := "ASC"
+	if filter.Descending {
+		direction = "DESC"
+	}
+	// Kiekvienam stulpeliui kryptis, NULL (pvz. niekada neprisijungę) visada gale
+	columns := strings.Split(orderBy, ", ")
+	for i, column := range columns {
+		columns[i] = column + " " + direction + " NULLS LAST"
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`
+        SELECT user_id, email, first_name, last_name, username,
+               role, is_active, avatar_url, email_verified,
+               created_at, updated_at, last_login_at
+        FROM "user"
+        %s
+        ORDER BY %s, user_id
+        LIMIT $%d OFFSET $%d
+    `, where, strings.Join(columns, ", "), len(args)-1, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		err := rows.Scan(
+			&user.UserID, &user.Email, &user.FirstName, &user.LastName,
+			&user.Username, &user.Role, &user.IsActive, &user.AvatarURL, &user.EmailVerified,
+			&user.CreatedAt, &user.UpdatedAt, &user.LastLoginAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+
+	return users, total, rows.Err()
+}
+
 // 🆕 Update user role
 func (r *UserRepository) UpdateUserRole(ctx context.Context, userID uuid.UUID, newRole string) error {
 	query := `UPDATE "user" SET role = $2, updated_at = NOW() WHERE user_id = $1`
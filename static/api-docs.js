@@ -0,0 +1,25 @@
+// API dokumentacija - Swagger UI 5.18.2 (static/swagger-ui, swagger-ui-dist, Apache 2.0)
+(function () {
+    const root = document.getElementById('api-docs');
+    if (!root) return;
+
+    // "Try it out" su sesijos slapuku: POST/PUT/PATCH/DELETE reikia CSRF token'o
+    let csrfHeaders = {};
+    try {
+        csrfHeaders = JSON.parse(document.body.getAttribute('hx-headers') || '{}');
+    } catch (e) {}
+
+    SwaggerUIBundle({
+        url: root.dataset.spec,
+        domNode: root,
+        deepLinking: true,
+        presets: [SwaggerUIBundle.presets.apis],
+        layout: 'BaseLayout',
+        requestInterceptor: function (request) {
+            Object.entries(csrfHeaders).forEach(([name, value]) => {
+                if (value) request.headers[name] = value;
+            });
+            return request;
+        },
+    });
+})();
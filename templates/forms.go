@@ -0,0 +1,107 @@
+package templates
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"battleNet/models"
+)
+
+// SignupForm - registracijos formos reikšmės (be slaptažodžių) ir klaidos
+type SignupForm struct {
+	Email     string
+	FirstName string
+	LastName  string
+	Username  string
+	Errors    map[string]string
+}
+
+// MovieForm - filmo formos reikšmės kaip įvestos ir klaidos pagal lauką.
+// Laukų pavadinimai sutampa su API (imdb_id, release_date, ...).
+type MovieForm struct {
+	ImdbID      string
+	Title       string
+	Overview    string
+	ReleaseDate string
+	VoteAverage string
+	VoteCount   string
+	Popularity  string
+	Runtime     string
+	Status      string
+	Genres      []string // pažymėti žanrai
+	Errors      map[string]string
+
+	GenreOptions []string // visi žanrai (genre lentelė)
+}
+
+// MovieFormFromValues atkuria formą iš pateiktų reikšmių, kad klaidos atveju
+// vartotojui nereikėtų visko vesti iš naujo
+func MovieFormFromValues(values url.Values, errors map[string]string) MovieForm {
+	return MovieForm{
+		ImdbID:      values.Get("imdb_id"),
+		Title:       values.Get("title"),
+		Overview:    values.Get("overview"),
+		ReleaseDate: values.Get("release_date"),
+		VoteAverage: values.Get("vote_average"),
+		VoteCount:   values.Get("vote_count"),
+		Popularity:  values.Get("popularity"),
+		Runtime:     values.Get("runtime"),
+		Status:      values.Get("status"),
+		Genres:      values["genres"],
+		Errors:      errors,
+	}
+}
+
+// MovieFormFromMovie - redagavimo formos pradinės reikšmės
+func MovieFormFromMovie(movie models.Movie) MovieForm {
+	form := MovieForm{Title: movie.Title, Genres: movie.GenreNames()}
+	if movie.ImdbID != nil {
+		form.ImdbID = *movie.ImdbID
+	}
+	if movie.Overview != nil {
+		form.Overview = *movie.Overview
+	}
+	if movie.ReleaseDate != nil {
+		form.ReleaseDate = movie.ReleaseDate.Format("2006-01-02")
+	}
+	if movie.VoteAverage != nil {
+		form.VoteAverage = strconv.FormatFloat(*movie.VoteAverage, 'f', 1, 64)
+	}
+	if movie.VoteCount != nil {
+		form.VoteCount = strconv.Itoa(*movie.VoteCount)
+	}
+	if movie.Popularity != nil {
+		form.Popularity = strconv.FormatFloat(*movie.Popularity, 'f', -1, 64)
+	}
+	if movie.Runtime != nil {
+		form.Runtime = strconv.Itoa(*movie.Runtime)
+	}
+	if movie.Status != nil {
+		form.Status = *movie.Status
+	}
+	return form
+}
+
+// formGroupClass - "form-group" ir "has-error", jei laukas turi klaidą
+func formGroupClass(errors map[string]string, field string) string {
+	if _, ok := errors[field]; ok {
+		return "form-group has-error"
+	}
+	return "form-group"
+}
+
+// fieldLabel - "first_name" -> "First name" (klaidos pranešimo pradžiai)
+func fieldLabel(field string) string {
+	label := strings.ReplaceAll(field, "_", " ")
+	switch label {
+	case "imdb id":
+		return "IMDB ID"
+	case "vote average":
+		return "Rating"
+	}
+	if label == "" {
+		return ""
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
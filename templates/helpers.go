@@ -1,8 +1,19 @@
 package templates
 
 import (
+	"battleNet/middlewaree"
 	"battleNet/models"
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
 )
 
 // Helper function to format integers
@@ -62,3 +73,377 @@ func Slice(s string, start, end int) string {
 func Printf(format string, args ...interface{}) string {
 	return fmt.Sprintf(format, args...)
 }
+
+// Flash - vienkartiniai pranešimai, perduodami per sesiją po redirect'o
+type Flash struct {
+	Success string
+	Error   string
+}
+
+// LinkedAccounts - profilio puslapio susietų paskyrų duomenys
+type LinkedAccounts struct {
+	Identities  []models.OAuth
+	Providers   []models.OAuthProvider
+	HasPassword bool
+}
+
+// IsLinked checks whether the user already has an identity for the provider
+func (a LinkedAccounts) IsLinked(provider string) bool {
+	for _, identity := range a.Identities {
+		if identity.Provider == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// ProviderDisplayName grąžina tiekėjo pavadinimą arba patį raktą, jei tiekėjas nebesukonfigūruotas
+func (a LinkedAccounts) ProviderDisplayName(provider string) string {
+	for _, p := range a.Providers {
+		if p.Name == provider {
+			return p.DisplayName
+		}
+	}
+	return provider
+}
+
+// describeDevice paverčia User-Agent į trumpą aprašymą, pvz. "Chrome on Windows"
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/") || strings.Contains(userAgent, "Opera"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/") || strings.Contains(userAgent, "CriOS/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	case strings.Contains(userAgent, "curl/"):
+		browser = "curl"
+	}
+
+	os := ""
+	switch {
+	case strings.Contains(userAgent, "Windows"):
+		os = "Windows"
+	case strings.Contains(userAgent, "iPhone") || strings.Contains(userAgent, "iPad"):
+		os = "iOS"
+	case strings.Contains(userAgent, "Mac OS X") || strings.Contains(userAgent, "Macintosh"):
+		os = "macOS"
+	case strings.Contains(userAgent, "Android"):
+		os = "Android"
+	case strings.Contains(userAgent, "Linux"):
+		os = "Linux"
+	}
+
+	if os == "" {
+		return browser
+	}
+	return browser + " on " + os
+}
+
+// TwoFactorSettings - 2FA puslapio būsena. Secret ir QRCode užpildomi registracijos
+// metu, RecoveryCodes - tik iškart po jų sugeneravimo.
+type TwoFactorSettings struct {
+	Enabled        bool
+	Required       bool
+	Secret         string
+	QRCode         templ.SafeURL // otpauth:// URI kaip PNG data URI; tuščias - rodomas tik raktas
+	RecoveryCodes  []string
+	RemainingCodes int
+}
+
+// AuditLogView - audito puslapio filtrai (kaip įvesti) ir rezultatai
+type AuditLogView struct {
+	Actor   string
+	Action  string
+	From    string
+	To      string
+	Actions []string
+	Events  []models.AuditEvent
+	PrevURL string
+	NextURL string
+	Error   string
+}
+
+// auditChangesText - kompaktiškas pakeitimų JSON atvaizdavimui lentelėje
+func auditChangesText(changes []byte) string {
+	var out bytes.Buffer
+	if err := json.Compact(&out, changes); err != nil || out.String() == "{}" {
+		return ""
+	}
+	return out.String()
+}
+
+// UserListView - moderatoriaus vartotojų sąrašo filtrai (kaip įvesti), puslapis ir rezultatai
+type UserListView struct {
+	Users         []models.User
+	Query         string
+	Role          string
+	Status        string
+	Verified      string
+	LastLoginFrom string
+	LastLoginTo   string
+	Sort          string
+	Order         string
+	Page          int
+	TotalPages    int
+	Total         int
+	Roles         []string
+	Error         string
+}
+
+func (v UserListView) values() url.Values {
+	values := url.Values{}
+	for key, value := range map[string]string{
+		"q":               v.Query,
+		"role":            v.Role,
+		"status":          v.Status,
+		"verified":        v.Verified,
+		"last_login_from": v.LastLoginFrom,
+		"last_login_to":   v.LastLoginTo,
+		"sort":            v.Sort,
+		"order":           v.Order,
+	} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	return values
+}
+
+// SortURL - nuoroda rikiuoti pagal column; paspaudus tą patį stulpelį kryptis apsiverčia
+func (v UserListView) SortURL(column string) templ.SafeURL {
+	values := v.values()
+	order := "asc"
+	if v.Sort == column && v.Order != "desc" {
+		order = "desc"
+	}
+	values.Set("sort", column)
+	values.Set("order", order)
+	return templ.SafeURL("/moderator/users?" + values.Encode())
+}
+
+// SortIndicator - rodyklė prie aktyvaus rikiavimo stulpelio
+func (v UserListView) SortIndicator(column string) string {
+	if v.Sort != column {
+		return ""
+	}
+	if v.Order == "desc" {
+		return " ▼"
+	}
+	return " ▲"
+}
+
+// PageURL - nuoroda į kitą puslapį su tais pačiais filtrais
+func (v UserListView) PageURL(page int) templ.SafeURL {
+	values := v.values()
+	values.Set("page", strconv.Itoa(page))
+	return templ.SafeURL("/moderator/users?" + values.Encode())
+}
+
+// MovieListView - /movies filtrai (kaip įvesti), facet'ai, puslapis ir rezultatai.
+// Visa būsena yra URL, todėl filtruotą sąrašą galima išsisaugoti ar nusiųsti.
+type MovieListView struct {
+	Movies     []models.Movie
+	Query      string   // paieškos tekstas
+	Genres     []string // pasirinkti žanrai
+	Statuses   []string
+	YearFrom   string
+	YearTo     string
+	RuntimeMin string
+	RuntimeMax string
+	MinVote    string
+	MinRating  string
+	Sort       string
+	Order      string
+	Facets     models.MovieFacets
+	Errors     map[string]string // netinkami filtrai
+	Limit      int
+	Page       int
+	TotalPages int
+	Total      int
+}
+
+func (v MovieListView) values() url.Values {
+	values := url.Values{}
+	if v.Query != "" {
+		values.Set("q", v.Query)
+	}
+	for _, genre := range v.Genres {
+		values.Add("genre", genre)
+	}
+	for _, status := range v.Statuses {
+		values.Add("status", status)
+	}
+	for key, value := range map[string]string{
+		"year_from":   v.YearFrom,
+		"year_to":     v.YearTo,
+		"runtime_min": v.RuntimeMin,
+		"runtime_max": v.RuntimeMax,
+		"min_vote":    v.MinVote,
+		"min_rating":  v.MinRating,
+		"sort":        v.Sort,
+		"order":       v.Order,
+	} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	if v.Limit != 0 && v.Limit != 20 {
+		values.Set("limit", strconv.Itoa(v.Limit))
+	}
+	return values
+}
+
+// Filtered - ar taikoma paieška ar bent vienas filtras
+func (v MovieListView) Filtered() bool {
+	return v.Query != "" || len(v.Genres) > 0 || len(v.Statuses) > 0 ||
+		v.YearFrom != "" || v.YearTo != "" || v.RuntimeMin != "" || v.RuntimeMax != "" ||
+		v.MinVote != "" || v.MinRating != ""
+}
+
+// GenreSelected - ar žanras pažymėtas filtre (be didžiųjų raidžių skirtumo)
+func (v MovieListView) GenreSelected(genre string) bool {
+	for _, selected := range v.Genres {
+		if strings.EqualFold(selected, genre) {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusSelected - ar statusas pažymėtas filtre
+func (v MovieListView) StatusSelected(status string) bool {
+	return slices.Contains(v.Statuses, status)
+}
+
+// PageURL - nuoroda į kitą puslapį su tais pačiais filtrais
+func (v MovieListView) PageURL(page int) templ.SafeURL {
+	values := v.values()
+	values.Set("page", strconv.Itoa(page))
+	return templ.SafeURL("/movies?" + values.Encode())
+}
+
+// RangeURL - tie patys filtrai, bet minKey/maxKey pakeisti intervalu
+// (pvz. year_from=1990&year_to=1999); grįžtama į pirmą puslapį
+func (v MovieListView) RangeURL(minKey, maxKey string, rng models.RangeFacet) templ.SafeURL {
+	values := v.values()
+	for key, bound := range map[string]*int{minKey: rng.Min, maxKey: rng.Max} {
+		if key == "" {
+			continue
+		}
+		if bound != nil {
+			values.Set(key, strconv.Itoa(*bound))
+		} else {
+			values.Del(key)
+		}
+	}
+	return templ.SafeURL("/movies?" + values.Encode())
+}
+
+// rangeActive - ar intervalas sutampa su įvestais filtrais
+func rangeActive(min, max string, rng models.RangeFacet) bool {
+	return min == optionalInt(rng.Min) && max == optionalInt(rng.Max)
+}
+
+func optionalInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return strconv.Itoa(*n)
+}
+
+// runtimeLabel - "Under 90 min", "90-119 min", "150+ min"
+func runtimeLabel(rng models.RangeFacet) string {
+	switch {
+	case rng.Min == nil && rng.Max != nil:
+		return "Under " + strconv.Itoa(*rng.Max+1) + " min"
+	case rng.Max == nil && rng.Min != nil:
+		return strconv.Itoa(*rng.Min) + "+ min"
+	case rng.Min != nil:
+		return strconv.Itoa(*rng.Min) + "-" + strconv.Itoa(*rng.Max) + " min"
+	}
+	return "Any"
+}
+
+// defaultSortLabel - ką reiškia tuščias sort
+func defaultSortLabel(query string) string {
+	if query != "" {
+		return "Default (best match)"
+	}
+	return "Default (recently added)"
+}
+
+// movieSortOptions - sort reikšmė ir pavadinimas /movies formoje (relevance - tik su paieška)
+var movieSortOptions = []struct{ Value, Label string }{
+	{"created_at", "Recently added"},
+	{"popularity", "Popularity"},
+	{"release_date", "Release date"},
+	{"title", "Title"},
+	{"rating", "TMDB rating"},
+	{"community_rating", "Community rating"},
+	{"reviews", "Number of reviews"},
+}
+
+// genreURL - filmų sąrašas, filtruotas pagal vieną žanrą
+func genreURL(genre string) templ.SafeURL {
+	return templ.SafeURL("/movies?" + url.Values{"genre": {genre}}.Encode())
+}
+
+// castShown - kiek aktorių rodoma filmo puslapyje (API grąžina visus)
+const castShown = 12
+
+// topCast - pirmi n aktorių pagal billing order
+func topCast(cast []models.CastCredit, n int) []models.CastCredit {
+	return cast[:min(n, len(cast))]
+}
+
+// releaseYear - "2010" arba "—", jei data nežinoma
+func releaseYear(date *time.Time) string {
+	if date == nil {
+		return "—"
+	}
+	return strconv.Itoa(date.Year())
+}
+
+// reviewCountLabel - "1 review", "5 reviews"
+func reviewCountLabel(n int) string {
+	if n == 1 {
+		return "1 review"
+	}
+	return formatInt(n) + " reviews"
+}
+
+// initials - "Christopher Nolan" -> "CN" (kai nėra nuotraukos)
+func initials(name string) string {
+	var out []rune
+	for _, word := range strings.Fields(name) {
+		out = append(out, []rune(word)[0])
+		if len(out) == 2 {
+			break
+		}
+	}
+	return strings.ToUpper(string(out))
+}
+
+// csrfHeaders - hx-headers reikšmė, kad HTMX užklausos siųstų CSRF token'ą antraštėje
+func csrfHeaders(ctx context.Context) string {
+	headers, _ := json.Marshal(map[string]string{middlewaree.CSRFHeader: middlewaree.CSRFToken(ctx)})
+	return string(headers)
+}
+
+// roleLabel - rolės pavadinimas didžiąja raide ("moderator" -> "Moderator")
+func roleLabel(role string) string {
+	if role == "" {
+		return ""
+	}
+	return strings.ToUpper(role[:1]) + role[1:]
+}